@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	AndroidCompatibilityCheckOff  = "off"
+	AndroidCompatibilityCheckWarn = "warn"
+	AndroidCompatibilityCheckFail = "fail"
+
+	gradleWrapperPropertiesRelPath = "android/gradle/wrapper/gradle-wrapper.properties"
+)
+
+var AndroidCompatibilityChecks = []string{AndroidCompatibilityCheckOff, AndroidCompatibilityCheckWarn, AndroidCompatibilityCheckFail}
+
+var (
+	// gradleDistributionRegexp matches the Gradle version of the wrapper's distributionUrl, e.g. `gradle-8.3-all.zip`.
+	gradleDistributionRegexp = regexp.MustCompile(`gradle-([0-9][0-9A-Za-z.\-]*?)-(?:all|bin)\.zip`)
+	// agpPluginRegexp matches the AGP version of the plugins block of settings.gradle(.kts) or build.gradle(.kts),
+	// e.g. `id "com.android.application" version "8.1.0"` or `id("com.android.application") version "8.1.0"`.
+	agpPluginRegexp = regexp.MustCompile(`id\s*\(?\s*["']com\.android\.(?:application|library)["']\s*\)?\s*version\s*["']([^"']+)["']`)
+	// agpClasspathRegexp matches the AGP version of the buildscript dependencies, e.g. `classpath 'com.android.tools.build:gradle:7.3.0'`.
+	agpClasspathRegexp = regexp.MustCompile(`com\.android\.tools\.build:gradle:([^"'\s)]+)`)
+	// javaVersionRegexp matches the version of the `java -version` output, e.g. `openjdk version "17.0.9"` or `java version "1.8.0_292"`.
+	javaVersionRegexp = regexp.MustCompile(`version "([^"]+)"`)
+)
+
+// flutterAndroidRequirement is the minimum Java, Gradle and Android Gradle Plugin version supported by the Flutter versions.
+type flutterAndroidRequirement struct {
+	flutter   *semver.Constraints
+	minJava   int
+	minGradle *semver.Version
+	minAGP    *semver.Version
+}
+
+// flutterAndroidRequirements are the minimum versions supported by the Gradle tooling of the Flutter releases.
+var flutterAndroidRequirements = []flutterAndroidRequirement{
+	{flutter: mustParseConstraint(">=3.29.0-0"), minJava: 17, minGradle: semver.MustParse("8.3"), minAGP: semver.MustParse("8.1.1")},
+	{flutter: mustParseConstraint(">=3.24.0-0 <3.29.0-0"), minJava: 11, minGradle: semver.MustParse("7.6.3"), minAGP: semver.MustParse("7.3.1")},
+	{flutter: mustParseConstraint(">=3.16.0-0 <3.24.0-0"), minJava: 11, minGradle: semver.MustParse("7.0.2"), minAGP: semver.MustParse("7.0.0")},
+	{flutter: mustParseConstraint("<3.16.0-0"), minJava: 8, minGradle: semver.MustParse("6.7"), minAGP: semver.MustParse("4.1.0")},
+}
+
+// gradleMinimumForJava is the first Gradle version able to run on the Java versions.
+var gradleMinimumForJava = []struct {
+	java   int
+	gradle *semver.Version
+}{
+	{java: 24, gradle: semver.MustParse("8.14")},
+	{java: 23, gradle: semver.MustParse("8.10")},
+	{java: 22, gradle: semver.MustParse("8.8")},
+	{java: 21, gradle: semver.MustParse("8.5")},
+	{java: 20, gradle: semver.MustParse("8.3")},
+	{java: 19, gradle: semver.MustParse("7.6")},
+	{java: 18, gradle: semver.MustParse("7.5")},
+	{java: 17, gradle: semver.MustParse("7.3")},
+}
+
+// agpRequirements are the minimum Java and Gradle versions required by the Android Gradle Plugin versions.
+var agpRequirements = []struct {
+	agp       *semver.Constraints
+	minJava   int
+	minGradle *semver.Version
+}{
+	{agp: mustParseConstraint(">=8.7.0-0"), minJava: 17, minGradle: semver.MustParse("8.9")},
+	{agp: mustParseConstraint(">=8.5.0-0"), minJava: 17, minGradle: semver.MustParse("8.7")},
+	{agp: mustParseConstraint(">=8.4.0-0"), minJava: 17, minGradle: semver.MustParse("8.6")},
+	{agp: mustParseConstraint(">=8.3.0-0"), minJava: 17, minGradle: semver.MustParse("8.4")},
+	{agp: mustParseConstraint(">=8.2.0-0"), minJava: 17, minGradle: semver.MustParse("8.2")},
+	{agp: mustParseConstraint(">=8.0.0-0"), minJava: 17, minGradle: semver.MustParse("8.0")},
+	{agp: mustParseConstraint(">=7.4.0-0"), minJava: 11, minGradle: semver.MustParse("7.5")},
+	{agp: mustParseConstraint(">=7.3.0-0"), minJava: 11, minGradle: semver.MustParse("7.4")},
+	{agp: mustParseConstraint(">=7.0.0-0"), minJava: 11, minGradle: semver.MustParse("7.0")},
+}
+
+// androidToolchain is the Java version of the machine and the Gradle and AGP versions of the project, nil or 0 if unknown.
+type androidToolchain struct {
+	java   int
+	gradle *semver.Version
+	agp    *semver.Version
+}
+
+// readGradleWrapperVersion returns the Gradle version of the project's Gradle wrapper, nil if there is no wrapper.
+func readGradleWrapperVersion(projectDir string) (*semver.Version, error) {
+	content, err := os.ReadFile(filepath.Join(projectDir, gradleWrapperPropertiesRelPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) != "distributionUrl" {
+			continue
+		}
+		match := gradleDistributionRegexp.FindStringSubmatch(value)
+		if match == nil {
+			return nil, fmt.Errorf("unknown distributionUrl: %s", strings.TrimSpace(value))
+		}
+		return semver.NewVersion(match[1])
+	}
+	return nil, nil
+}
+
+// readAGPVersion returns the Android Gradle Plugin version of the project from the plugins block of settings.gradle(.kts),
+// or from the plugins block or buildscript dependencies of the root build.gradle(.kts), nil if not found.
+func readAGPVersion(projectDir string) (*semver.Version, error) {
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts", "build.gradle", "build.gradle.kts"} {
+		content, err := os.ReadFile(filepath.Join(projectDir, "android", name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, re := range []*regexp.Regexp{agpPluginRegexp, agpClasspathRegexp} {
+			if match := re.FindStringSubmatch(string(content)); match != nil {
+				return semver.NewVersion(match[1])
+			}
+		}
+	}
+	return nil, nil
+}
+
+// parseJavaMajorVersion returns the major version from the `java -version` output, e.g. 17 or 8 (for 1.8).
+func parseJavaMajorVersion(out string) (int, error) {
+	match := javaVersionRegexp.FindStringSubmatch(out)
+	if match == nil {
+		return 0, fmt.Errorf("no version in java output: %s", out)
+	}
+
+	parts := strings.FieldsFunc(match[1], func(r rune) bool { return r == '.' || r == '_' || r == '-' || r == '+' })
+	if len(parts) > 1 && parts[0] == "1" {
+		// Legacy version scheme: 1.8.0_292
+		parts = parts[1:]
+	}
+	return strconv.Atoi(parts[0])
+}
+
+// checkAndroidCompatibility compares the Android toolchain to the requirements of the Flutter version
+// and of the project's Android Gradle Plugin, and returns the incompatibilities with their remediation.
+func checkAndroidCompatibility(flutter *semver.Version, toolchain androidToolchain) []string {
+	var issues []string
+
+	for _, requirement := range flutterAndroidRequirements {
+		if !satisfiesConstraint(flutter, requirement.flutter) {
+			continue
+		}
+		if toolchain.java != 0 && toolchain.java < requirement.minJava {
+			issues = append(issues, fmt.Sprintf("Flutter %s requires Java %d or newer, but Java %d is used: install a newer JDK or set it with `flutter config --jdk-dir`", flutter, requirement.minJava, toolchain.java))
+		}
+		if toolchain.gradle != nil && toolchain.gradle.LessThan(requirement.minGradle) {
+			issues = append(issues, fmt.Sprintf("Flutter %s requires Gradle %s or newer, but the project uses Gradle %s: update distributionUrl in %s", flutter, requirement.minGradle, toolchain.gradle, gradleWrapperPropertiesRelPath))
+		}
+		if toolchain.agp != nil && toolchain.agp.LessThan(requirement.minAGP) {
+			issues = append(issues, fmt.Sprintf("Flutter %s requires Android Gradle Plugin %s or newer, but the project uses %s: update the com.android.application plugin version in android/settings.gradle", flutter, requirement.minAGP, toolchain.agp))
+		}
+		break
+	}
+
+	if toolchain.java != 0 && toolchain.gradle != nil {
+		for _, requirement := range gradleMinimumForJava {
+			if toolchain.java >= requirement.java {
+				if toolchain.gradle.LessThan(requirement.gradle) {
+					issues = append(issues, fmt.Sprintf("Gradle %s does not support Java %d, Gradle %s or newer is required: update distributionUrl in %s or use an older JDK", toolchain.gradle, toolchain.java, requirement.gradle, gradleWrapperPropertiesRelPath))
+				}
+				break
+			}
+		}
+	}
+
+	if toolchain.agp != nil {
+		for _, requirement := range agpRequirements {
+			if !satisfiesConstraint(toolchain.agp, requirement.agp) {
+				continue
+			}
+			if toolchain.java != 0 && toolchain.java < requirement.minJava {
+				issues = append(issues, fmt.Sprintf("Android Gradle Plugin %s requires Java %d or newer, but Java %d is used: install a newer JDK or set it with `flutter config --jdk-dir`", toolchain.agp, requirement.minJava, toolchain.java))
+			}
+			if toolchain.gradle != nil && toolchain.gradle.LessThan(requirement.minGradle) {
+				issues = append(issues, fmt.Sprintf("Android Gradle Plugin %s requires Gradle %s or newer, but the project uses Gradle %s: update distributionUrl in %s", toolchain.agp, requirement.minGradle, toolchain.gradle, gradleWrapperPropertiesRelPath))
+			}
+			break
+		}
+	}
+
+	return issues
+}
+
+// checkAndroidToolchain checks if the Java version and the project's Gradle and Android Gradle Plugin versions
+// are supported by the installed Flutter version, before the build fails on them.
+//
+// Incompatibilities are reported as warnings, or fail the Step, depending on the android_compatibility_check input.
+func (f *FlutterInstaller) checkAndroidToolchain() error {
+	if f.Input.AndroidCompatibilityCheck == AndroidCompatibilityCheckOff {
+		return nil
+	}
+	if info, err := os.Stat(filepath.Join(f.Input.ProjectLocation, "android")); err != nil || !info.IsDir() {
+		f.Debugf("No android directory in the project, skipping the Android toolchain check")
+		return nil
+	}
+
+	f.Infof("Check Android toolchain compatibility")
+
+	current, err := f.NewFlutterVersionFromCurrent()
+	if err != nil || current.version == nil {
+		f.Warnf("Failed to get the installed Flutter version, skipping the Android toolchain check: %v", err)
+		return nil
+	}
+
+	var toolchain androidToolchain
+	if toolchain.gradle, err = readGradleWrapperVersion(f.Input.ProjectLocation); err != nil {
+		f.Warnf("Failed to read the Gradle wrapper version: %s", err)
+	}
+	if toolchain.agp, err = readAGPVersion(f.Input.ProjectLocation); err != nil {
+		f.Warnf("Failed to read the Android Gradle Plugin version: %s", err)
+	}
+	javaCmd := f.CmdFactory.Create("java", []string{"-version"}, nil)
+	if out, err := javaCmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		f.Debugf("java -version: %s %s", err, out)
+	} else if toolchain.java, err = parseJavaMajorVersion(out); err != nil {
+		f.Debugf("%s", err)
+	}
+	f.Printf("Flutter %s, Java %d, Gradle %v, Android Gradle Plugin %v", current.version, toolchain.java, toolchain.gradle, toolchain.agp)
+
+	issues := checkAndroidCompatibility(current.version, toolchain)
+	if len(issues) == 0 {
+		f.Donef("The Android toolchain is compatible")
+		return nil
+	}
+	for _, issue := range issues {
+		f.Warnf("- %s", issue)
+	}
+	if f.Input.AndroidCompatibilityCheck == AndroidCompatibilityCheckFail {
+		return fmt.Errorf("%d Android toolchain incompatibilities found", len(issues))
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func Test_readAndroidProjectVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantGradle string
+		wantAGP    string
+	}{
+		{
+			name: "Plugins block",
+			files: map[string]string{
+				gradleWrapperPropertiesRelPath: "distributionBase=GRADLE_USER_HOME\ndistributionUrl=https\\://services.gradle.org/distributions/gradle-8.3-all.zip\n",
+				"android/settings.gradle":      "plugins {\n    id \"dev.flutter.flutter-plugin-loader\" version \"1.0.0\"\n    id \"com.android.application\" version \"8.1.0\" apply false\n}\n",
+			},
+			wantGradle: "8.3.0",
+			wantAGP:    "8.1.0",
+		},
+		{
+			name: "Kotlin DSL",
+			files: map[string]string{
+				gradleWrapperPropertiesRelPath: "distributionUrl=https\\://services.gradle.org/distributions/gradle-8.10.2-bin.zip\n",
+				"android/settings.gradle.kts":  "plugins {\n    id(\"com.android.application\") version \"8.7.0\" apply false\n}\n",
+			},
+			wantGradle: "8.10.2",
+			wantAGP:    "8.7.0",
+		},
+		{
+			name: "Buildscript classpath",
+			files: map[string]string{
+				"android/build.gradle": "buildscript {\n    dependencies {\n        classpath 'com.android.tools.build:gradle:7.3.0'\n    }\n}\n",
+			},
+			wantAGP: "7.3.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for pth, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, pth), content)
+			}
+
+			gradle, err := readGradleWrapperVersion(dir)
+			if err != nil {
+				t.Fatalf("readGradleWrapperVersion() error = %v", err)
+			}
+			if got := versionOrEmpty(gradle); got != tt.wantGradle {
+				t.Errorf("readGradleWrapperVersion() = %s, want %s", got, tt.wantGradle)
+			}
+
+			agp, err := readAGPVersion(dir)
+			if err != nil {
+				t.Fatalf("readAGPVersion() error = %v", err)
+			}
+			if got := versionOrEmpty(agp); got != tt.wantAGP {
+				t.Errorf("readAGPVersion() = %s, want %s", got, tt.wantAGP)
+			}
+		})
+	}
+}
+
+func versionOrEmpty(v *semver.Version) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func Test_parseJavaMajorVersion(t *testing.T) {
+	tests := []struct {
+		out  string
+		want int
+	}{
+		{out: "openjdk version \"17.0.9\" 2023-10-17\nOpenJDK Runtime Environment Temurin-17.0.9+9", want: 17},
+		{out: "java version \"1.8.0_292\"\nJava(TM) SE Runtime Environment", want: 8},
+		{out: "openjdk version \"21\" 2023-09-19", want: 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			got, err := parseJavaMajorVersion(tt.out)
+			if err != nil || got != tt.want {
+				t.Errorf("parseJavaMajorVersion() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkAndroidCompatibility(t *testing.T) {
+	tests := []struct {
+		name      string
+		flutter   string
+		toolchain androidToolchain
+		want      int
+	}{
+		{
+			name:      "Compatible",
+			flutter:   "3.27.1",
+			toolchain: androidToolchain{java: 17, gradle: semver.MustParse("8.3"), agp: semver.MustParse("8.1.0")},
+		},
+		{
+			name:      "Unknown toolchain",
+			flutter:   "3.29.0",
+			toolchain: androidToolchain{},
+		},
+		{
+			name:      "Outdated for Flutter",
+			flutter:   "3.29.0",
+			toolchain: androidToolchain{java: 11, gradle: semver.MustParse("7.5"), agp: semver.MustParse("7.4.2")},
+			want:      3,
+		},
+		{
+			name:      "Gradle does not support Java",
+			flutter:   "3.24.5",
+			toolchain: androidToolchain{java: 21, gradle: semver.MustParse("8.3")},
+			want:      1,
+		},
+		{
+			name:      "AGP requires newer Java and Gradle",
+			flutter:   "3.22.3",
+			toolchain: androidToolchain{java: 11, gradle: semver.MustParse("7.6.3"), agp: semver.MustParse("8.1.0")},
+			want:      2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkAndroidCompatibility(semver.MustParse(tt.flutter), tt.toolchain); len(got) != tt.want {
+				t.Errorf("checkAndroidCompatibility() = %v, want %d issues", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const androidToolchainValidatorName = "Android toolchain"
+
+// androidLicenseHashes are the hashes of the Android SDK license texts, written to <sdk>/licenses/<license> when accepting them.
+var androidLicenseHashes = map[string][]string{
+	"android-sdk-license":           {"8933bad161af4178b1185d1a37fbf41ea5269c55", "d56f5187479451eabf01fb78af6dfcb131a6481e", "24333f8a63b6825ea9c5514f83c2829b004d1fee"},
+	"android-sdk-preview-license":   {"84831b9409646a918e30573bab4c9c91346d8abd"},
+	"android-sdk-arm-dbt-license":   {"859f317696f67ef3d7f30a50a5560e7834b43903"},
+	"android-googletv-license":      {"601085b94cd77f0b54ff86406957099ebe79c4d6"},
+	"google-gdk-license":            {"33b6a2b64607f11b759f320ef9dff4ae5c47d97a"},
+	"intel-android-extra-license":   {"d975f751698a77b662f1254ddbeed3901e976f5a"},
+	"mips-android-sysimage-license": {"e9acab5b5fbb560a72cfaecce8946896ff6aab9d"},
+}
+
+// findAndroidSDK returns the Android SDK directory from the ANDROID_HOME or ANDROID_SDK_ROOT environment variables,
+// or from the `android-sdk` setting of `flutter config`.
+func (f *FlutterInstaller) findAndroidSDK() (string, error) {
+	for _, key := range []string{"ANDROID_HOME", "ANDROID_SDK_ROOT"} {
+		if dir := f.EnvRepo.Get(key); dir != "" {
+			f.Debugf("Android SDK from %s: %s", key, dir)
+			return dir, nil
+		}
+	}
+
+	configCmd := f.CmdFactory.Create("flutter", []string{"config", "--machine"}, nil)
+	out, err := configCmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("get flutter config: %s %s", err, out)
+	}
+	config, err := parseFlutterConfigMachine(out)
+	if err != nil {
+		return "", err
+	}
+	if dir, ok := config["android-sdk"].(string); ok && dir != "" {
+		f.Debugf("Android SDK from flutter config: %s", dir)
+		return dir, nil
+	}
+
+	return "", fmt.Errorf("ANDROID_HOME, ANDROID_SDK_ROOT and the android-sdk flutter config setting are not set")
+}
+
+// findSDKManager returns the sdkmanager of the Android SDK's command-line tools, the latest version first.
+func findSDKManager(sdkDir string) (string, bool) {
+	candidates := []string{filepath.Join(sdkDir, "cmdline-tools", "latest", "bin", "sdkmanager")}
+	versioned, _ := filepath.Glob(filepath.Join(sdkDir, "cmdline-tools", "*", "bin", "sdkmanager"))
+	slices.Sort(versioned)
+	slices.Reverse(versioned)
+	candidates = append(candidates, versioned...)
+	candidates = append(candidates, filepath.Join(sdkDir, "tools", "bin", "sdkmanager"))
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+	if pth, err := exec.LookPath("sdkmanager"); err == nil {
+		return pth, true
+	}
+	return "", false
+}
+
+// writeAndroidLicenseHashes adds the known license hashes to the license files of the Android SDK, keeping the existing ones.
+func writeAndroidLicenseHashes(sdkDir string) error {
+	licensesDir := filepath.Join(sdkDir, "licenses")
+	if err := os.MkdirAll(licensesDir, 0755); err != nil {
+		return err
+	}
+
+	for license, hashes := range androidLicenseHashes {
+		pth := filepath.Join(licensesDir, license)
+		content, err := os.ReadFile(pth)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		accepted := strings.Fields(string(content))
+		for _, hash := range hashes {
+			if !slices.Contains(accepted, hash) {
+				accepted = append(accepted, hash)
+			}
+		}
+		if err := os.WriteFile(pth, []byte("\n"+strings.Join(accepted, "\n")), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// androidLicensesAccepted checks if the Android toolchain validator of `flutter doctor` does not report unaccepted licenses.
+func androidLicensesAccepted(validators []doctorValidator) (bool, error) {
+	for _, validator := range validators {
+		if !strings.HasPrefix(validator.Name, androidToolchainValidatorName) {
+			continue
+		}
+		for _, message := range validator.Messages {
+			if message.Type != "information" && strings.Contains(strings.ToLower(message.Message), "license") {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("no %s validator in flutter doctor output", androidToolchainValidatorName)
+}
+
+// acceptAndroidLicenses accepts the licenses of the Android SDK, so that Android builds do not fail on fresh machines.
+//
+// It accepts them with `sdkmanager --licenses` if the command-line tools are installed, otherwise it writes the known license hashes.
+// The result is verified with the Android toolchain validator of `flutter doctor`.
+func (f *FlutterInstaller) acceptAndroidLicenses() error {
+	f.Infof("Accept Android SDK licenses")
+
+	sdkDir, err := f.findAndroidSDK()
+	if err != nil {
+		return fmt.Errorf("find Android SDK: %w", err)
+	}
+
+	accepted := false
+	if sdkManager, found := findSDKManager(sdkDir); found {
+		cmdOpts := command.Opts{
+			Stdin: strings.NewReader(strings.Repeat("y\n", 50)),
+			Env:   []string{"ANDROID_HOME=" + sdkDir},
+		}
+		licensesCmd := f.CmdFactory.Create(sdkManager, []string{"--licenses", "--sdk_root=" + sdkDir}, &cmdOpts)
+		f.Donef("$ %s", licensesCmd.PrintableCommandArgs())
+		if out, err := licensesCmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+			f.Warnf("Failed to accept licenses with sdkmanager, writing the license hashes instead: %s", err)
+			f.Debugf("%s", out)
+		} else {
+			accepted = true
+		}
+	} else {
+		f.Debugf("sdkmanager not found in %s", sdkDir)
+	}
+
+	if !accepted {
+		if err := writeAndroidLicenseHashes(sdkDir); err != nil {
+			return fmt.Errorf("write license hashes: %w", err)
+		}
+		f.Printf("License hashes written to %s", filepath.Join(sdkDir, "licenses"))
+	}
+
+	validators, _, err := f.flutterDoctorReport()
+	if err != nil {
+		return fmt.Errorf("verify licenses: %w", err)
+	}
+	ok, err := androidLicensesAccepted(validators)
+	if err != nil {
+		return fmt.Errorf("verify licenses: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("flutter doctor still reports unaccepted Android licenses")
+	}
+	f.Donef("Android SDK licenses accepted")
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_writeAndroidLicenseHashes(t *testing.T) {
+	sdkDir := t.TempDir()
+	const customHash = "0123456789abcdef0123456789abcdef01234567"
+	writeTestFile(t, filepath.Join(sdkDir, "licenses", "android-sdk-license"), "\n"+customHash)
+
+	for i := 0; i < 2; i++ {
+		if err := writeAndroidLicenseHashes(sdkDir); err != nil {
+			t.Fatalf("writeAndroidLicenseHashes() error = %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(sdkDir, "licenses", "android-sdk-license"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := strings.Fields(string(content))
+	if len(hashes) != len(androidLicenseHashes["android-sdk-license"])+1 || hashes[0] != customHash {
+		t.Errorf("android-sdk-license = %v, want the existing hash and the known hashes once", hashes)
+	}
+	if _, err := os.Stat(filepath.Join(sdkDir, "licenses", "android-sdk-preview-license")); err != nil {
+		t.Errorf("android-sdk-preview-license not written: %v", err)
+	}
+}
+
+func Test_androidLicensesAccepted(t *testing.T) {
+	accepted := `[{"name": "Android toolchain - develop for Android devices", "status": "installed", "messages": [{"message": "All Android licenses accepted.", "type": "information"}]}]`
+	tests := []struct {
+		name    string
+		output  string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "Accepted",
+			output: accepted,
+			want:   true,
+		},
+		{
+			name:   "Not accepted",
+			output: doctorMachineOutput,
+		},
+		{
+			name:    "No Android toolchain validator",
+			output:  `[{"name": "Flutter", "status": "installed"}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validators, _, err := parseDoctorReport(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := androidLicensesAccepted(validators)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("androidLicensesAccepted() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("androidLicensesAccepted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ASDFPluginDefaultURL is the git URL of the community maintained asdf flutter plugin.
+	ASDFPluginDefaultURL    = "https://github.com/asdf-community/asdf-flutter.git"
+	asdfToolVersionsRelPath = ".tool-versions"
+	// asdfPluginPrerequisites are the tools required by the asdf-flutter plugin.
+	asdfPluginPrerequisites = "jq curl"
+)
+
+// toolVersionsRequestsFlutter checks if the .tool-versions content has a flutter entry.
+func toolVersionsRequestsFlutter(content string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "flutter" {
+			return true
+		}
+	}
+	return false
+}
+
+// projectRequiresASDFFlutter checks if the project's .tool-versions file requests Flutter.
+func (f *FlutterInstaller) projectRequiresASDFFlutter() bool {
+	content, err := os.ReadFile(filepath.Join(f.Input.ProjectLocation, asdfToolVersionsRelPath))
+	if err != nil {
+		return false
+	}
+	return toolVersionsRequestsFlutter(string(content))
+}
+
+// asdfPluginSetup is the result of setting up the asdf flutter plugin, which is attempted at most once per run.
+type asdfPluginSetup struct {
+	err error
+}
+
+// ensureASDFPlugin adds the flutter plugin to asdf if it is not installed yet, and checks out the pinned ref.
+//
+// The result is remembered: a failed attempt is not retried for each required version.
+func (f *FlutterInstaller) ensureASDFPlugin(installed bool) error {
+	if f.asdfPlugin == nil {
+		f.asdfPlugin = &asdfPluginSetup{err: f.setupASDFPlugin(installed)}
+	}
+	return f.asdfPlugin.err
+}
+
+func (f *FlutterInstaller) setupASDFPlugin(installed bool) error {
+	if !installed {
+		if err := f.asdfAddPlugin(); err != nil {
+			return err
+		}
+	}
+	if f.Input.ASDFPluginRef == "" {
+		return nil
+	}
+
+	cmd := f.CmdFactory.Create("asdf", []string{"plugin", "update", "flutter", f.Input.ASDFPluginRef}, nil)
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("check out plugin ref %s: %s %s", f.Input.ASDFPluginRef, err, out)
+	}
+
+	return nil
+}
+
+// asdfAddPlugin adds the flutter plugin to asdf from the configured git URL.
+func (f *FlutterInstaller) asdfAddPlugin() error {
+	var missing []string
+	for _, tool := range strings.Fields(asdfPluginPrerequisites) {
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("prerequisites of the asdf flutter plugin are not installed: %s", strings.Join(missing, ", "))
+	}
+
+	f.Infof("Adding asdf flutter plugin from %s", f.Input.ASDFPluginURL)
+
+	cmd := f.CmdFactory.Create("asdf", []string{"plugin", "add", "flutter", f.Input.ASDFPluginURL}, nil)
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("add plugin: %s %s", err, out)
+	}
+
+	return nil
+}
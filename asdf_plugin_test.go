@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_toolVersionsRequestsFlutter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{
+			name:    "Flutter entry",
+			content: "ruby 3.3.0\nflutter 3.22.0-stable\n",
+			want:    true,
+		},
+		{
+			name:    "Flutter entry with fallback versions",
+			content: "flutter 3.24.5-stable 3.22.0-stable # comment\n",
+			want:    true,
+		},
+		{
+			name:    "No Flutter entry",
+			content: "ruby 3.3.0\nnodejs 20.11.0\n",
+		},
+		{
+			name:    "Commented Flutter entry",
+			content: "# flutter 3.22.0-stable\n",
+		},
+		{
+			name:    "Flutter entry without version",
+			content: "flutter\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolVersionsRequestsFlutter(tt.content); got != tt.want {
+				t.Errorf("toolVersionsRequestsFlutter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ensureASDFPlugin_remembersFailure(t *testing.T) {
+	addErr := errors.New("add plugin: exit status 1")
+	// No command factory: the setup must not be attempted again.
+	f := FlutterInstaller{asdfPlugin: &asdfPluginSetup{err: addErr}}
+
+	for i := 0; i < 2; i++ {
+		if err := f.ensureASDFPlugin(false); !errors.Is(err, addErr) {
+			t.Errorf("ensureASDFPlugin() error = %v, want %v", err, addErr)
+		}
+	}
+}
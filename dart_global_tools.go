@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var dartPackageNameRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// dartGlobalTool is an entry of the dart_global_tools input, e.g. `melos@6.3.2`.
+type dartGlobalTool struct {
+	name string
+	// version is empty if the latest version is required.
+	version string
+}
+
+func (t dartGlobalTool) String() string {
+	if t.version == "" {
+		return t.name
+	}
+	return t.name + "@" + t.version
+}
+
+// parseDartGlobalTools parses the comma or newline separated `name@version` entries, the version is optional.
+func parseDartGlobalTools(input string) ([]dartGlobalTool, error) {
+	var tools []dartGlobalTool
+	for _, field := range splitListInput(input) {
+		name, version, _ := strings.Cut(field, "@")
+		name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+		if !dartPackageNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid package name: %s", name)
+		}
+		if strings.ContainsAny(version, " \t") {
+			return nil, fmt.Errorf("invalid version of %s: %s", name, version)
+		}
+
+		tools = append(tools, dartGlobalTool{name: name, version: version})
+	}
+	return tools, nil
+}
+
+// pubCacheDir returns the pub cache directory: PUB_CACHE or ~/.pub-cache.
+func (f *FlutterInstaller) pubCacheDir() string {
+	if dir := f.EnvRepo.Get("PUB_CACHE"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".pub-cache")
+}
+
+// activatedVersion returns the version of the globally activated package from the pub cache, empty if it is not activated.
+func activatedVersion(pubCacheDir, name string) (string, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `yaml:"version"`
+		} `yaml:"packages"`
+	}
+	if err := readYAMLIfExists(filepath.Join(pubCacheDir, "global_packages", name, "pubspec.lock"), &lock); err != nil {
+		return "", err
+	}
+	return lock.Packages[name].Version, nil
+}
+
+// activateDartGlobalTools activates the packages of the dart_global_tools input with the installed Dart SDK,
+// and adds the pub cache bin directory to the PATH.
+//
+// Packages already activated at the required version are not activated again.
+func (f *FlutterInstaller) activateDartGlobalTools() error {
+	tools, err := parseDartGlobalTools(f.Input.DartGlobalTools)
+	if err != nil {
+		return err
+	}
+	if len(tools) == 0 {
+		return nil
+	}
+
+	f.Infof("Activate global Dart tools")
+
+	dart, err := f.installedDart()
+	if err != nil {
+		return err
+	}
+
+	pubCacheDir := f.pubCacheDir()
+	for _, tool := range tools {
+		if err := f.activateDartGlobalTool(dart, pubCacheDir, tool); err != nil {
+			return err
+		}
+	}
+
+	return f.addPubCacheBinToPath(pubCacheDir)
+}
+
+// activateDartGlobalTool activates the package with `dart pub global activate`,
+// unless it is already activated at the required version.
+func (f *FlutterInstaller) activateDartGlobalTool(dart, pubCacheDir string, tool dartGlobalTool) error {
+	activated, err := activatedVersion(pubCacheDir, tool.name)
+	if err != nil {
+		f.Debugf("Read activated version of %s: %s", tool.name, err)
+	}
+	if activated != "" && (tool.version == "" || tool.version == activated) {
+		f.Donef("%s %s is already activated", tool.name, activated)
+		return nil
+	}
+
+	args := []string{"pub", "global", "activate", tool.name}
+	if tool.version != "" {
+		args = append(args, tool.version)
+	}
+	cmd := f.CmdFactory.Create(dart, args, nil)
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("activate %s: %s %s", tool, err, out)
+	}
+	return nil
+}
+
+// addPubCacheBinToPath adds the bin directory of the pub cache to the PATH, so the activated packages can be run.
+func (f *FlutterInstaller) addPubCacheBinToPath(pubCacheDir string) error {
+	binDir := filepath.Join(pubCacheDir, "bin")
+	if slices.Contains(filepath.SplitList(os.Getenv("PATH")), binDir) {
+		return nil
+	}
+	return f.prependToPath(binDir)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func Test_parseDartGlobalTools(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []dartGlobalTool
+		wantErr bool
+	}{
+		{
+			name:  "Pinned and latest versions",
+			input: "melos@6.3.2, flutterfire_cli@1.0.0\nvery_good_cli",
+			want: []dartGlobalTool{
+				{name: "melos", version: "6.3.2"},
+				{name: "flutterfire_cli", version: "1.0.0"},
+				{name: "very_good_cli"},
+			},
+		},
+		{
+			name:    "Invalid package name",
+			input:   "very-good-cli",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDartGlobalTools(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDartGlobalTools() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseDartGlobalTools() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_activatedVersion(t *testing.T) {
+	pubCacheDir := t.TempDir()
+	writeTestFile(t, filepath.Join(pubCacheDir, "global_packages", "melos", "pubspec.lock"), `packages:
+  ansi_styles:
+    dependency: transitive
+    source: hosted
+    version: "0.3.2+1"
+  melos:
+    dependency: "direct main"
+    source: hosted
+    version: "6.3.2"
+sdks:
+  dart: ">=3.2.0 <4.0.0"
+`)
+
+	if got, err := activatedVersion(pubCacheDir, "melos"); err != nil || got != "6.3.2" {
+		t.Errorf("activatedVersion(melos) = %s, %v, want 6.3.2", got, err)
+	}
+	if got, err := activatedVersion(pubCacheDir, "very_good_cli"); err != nil || got != "" {
+		t.Errorf("activatedVersion(very_good_cli) = %s, %v, want not activated", got, err)
+	}
+}
+
+func Test_activateDartGlobalTool(t *testing.T) {
+	pubCacheDir := t.TempDir()
+	writeTestFile(t, filepath.Join(pubCacheDir, "global_packages", "melos", "pubspec.lock"), "packages:\n  melos:\n    version: \"6.3.2\"\n")
+
+	tests := []struct {
+		name        string
+		tool        dartGlobalTool
+		wantCreated []string
+	}{
+		{
+			name: "Activated at the required version",
+			tool: dartGlobalTool{name: "melos", version: "6.3.2"},
+		},
+		{
+			name: "Activated, any version",
+			tool: dartGlobalTool{name: "melos"},
+		},
+		{
+			name:        "Activated at another version",
+			tool:        dartGlobalTool{name: "melos", version: "6.3.3"},
+			wantCreated: []string{"dart pub global activate melos 6.3.3"},
+		},
+		{
+			name:        "Not activated",
+			tool:        dartGlobalTool{name: "very_good_cli"},
+			wantCreated: []string{"dart pub global activate very_good_cli"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &fakeCommandFactory{}
+			if err := newFakeInstaller(factory, Input{}).activateDartGlobalTool("dart", pubCacheDir, tt.tool); err != nil {
+				t.Fatalf("activateDartGlobalTool() error = %v", err)
+			}
+			if !slices.Equal(factory.created, tt.wantCreated) {
+				t.Errorf("activateDartGlobalTool() ran %q, want %q", factory.created, tt.wantCreated)
+			}
+		})
+	}
+}
@@ -7,23 +7,91 @@ import (
 
 // EnssureFlutterVersion ensures that the required Flutter version is installed and set as default.
 //
-// It gets the required version from the input or project files, checks if it is already installed,
-// and installs it using the available install types (FVM, ASDF, Manual).
+// It gets the ordered list of acceptable versions from the input or project files.
+// Channels are pinned to their current release (or the one in the lock file), so the installed SDK is reproducible.
+// First it checks if any already installed SDK satisfies an entry (in order), and only if none does,
+// it installs the first installable entry using the available install types (FVM, ASDF, Manual).
 func (f *FlutterInstaller) EnsureFlutterVersion() error {
-	requiredVersion, err := f.NewFlutterVersionFromInputAndProject()
+	specifiers, err := f.NewVersionSpecifiersFromInputAndProject()
 	if err != nil {
 		return fmt.Errorf("fetch required Flutter version: %w", err)
 	}
-	f.Infof("Required Flutter: %s", f.NewVersionString(requiredVersion))
+	specifiers, err = f.pinChannels(specifiers)
+	if err != nil {
+		return fmt.Errorf("pin Flutter channel: %w", err)
+	}
+	f.Infof("Required Flutter: %s", specifiersString(specifiers))
 
-	currentVersionString := f.NewVersionString(requiredVersion)
-	installed, currentVersion := f.compareVersionToCurrent(requiredVersion, true)
-	if installed {
-		f.Donef("Flutter %s is already installed", currentVersionString)
+	currentVersion, err := f.NewFlutterVersionFromCurrent()
+	if err != nil {
+		f.Debugf("get current Flutter version: %s", err)
+	} else if i, found := currentVersionMatch(specifiers, currentVersion, f.Input.FVMScope); found {
+		f.Donef("Flutter %s is already installed (matching entry #%d: %s)", f.NewVersionString(currentVersion), i+1, specifiers[i])
 		return nil
 	}
 
-	fvm, asdf, manual := f.NewFlutterInstallTypeFVM(), f.NewFlutterInstallTypeASDF(), f.NewFlutterInstallTypeManual()
+	installTypes := f.installTypesFor(currentVersion)
+
+	for i, specifier := range specifiers {
+		for _, installType := range installTypes {
+			version, err := f.setDefaultIfInstalled(installType, specifier)
+			if err == nil {
+				f.Donef("Flutter %s is already installed and set as default with %s (matching entry #%d: %s)", f.NewVersionString(version), installType.Name, i+1, specifier)
+				return nil
+			}
+			f.Debugf("Set Flutter %s default if already installed: %s", specifier, err)
+		}
+	}
+
+	for i, specifier := range specifiers {
+		for _, installType := range installTypes {
+			version, err := f.installAndSetDefault(installType, specifier)
+			if err == nil {
+				f.Donef("Installed and set default Flutter %s with %s (matching entry #%d: %s)", f.NewVersionString(version), installType.Name, i+1, specifier)
+				return nil
+			}
+			f.Debugf("Install and set default Flutter %s: %s", specifier, err)
+		}
+	}
+
+	return fmt.Errorf("installing Flutter %s: could not be installed or set as default", specifiersString(specifiers))
+}
+
+// currentVersionMatch returns the index of the first specifier satisfied by the current Flutter version.
+//
+// In FVM project scope the current version is never accepted: the project's .fvm/flutter_sdk link
+// is created by setting the version, even if it is already the current one.
+func currentVersionMatch(specifiers []versionSpecifier, version flutterVersion, fvmScope string) (int, bool) {
+	if fvmScope == FVMScopeProject {
+		return -1, false
+	}
+	for i, specifier := range specifiers {
+		if specifier.matches(version) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func specifiersString(specifiers []versionSpecifier) string {
+	var entries []string
+	for _, specifier := range specifiers {
+		entries = append(entries, specifier.String())
+	}
+	return strings.Join(entries, ", otherwise ")
+}
+
+// installTypesFor returns the available install types in the order they should be tried.
+//
+// The tool managing the current Flutter installation is preferred.
+// If a bundle URL is specified, only the manual install type is used, as it is the only one able to install from a bundle.
+func (f *FlutterInstaller) installTypesFor(currentVersion flutterVersion) []*FlutterInstallType {
+	manual := f.NewFlutterInstallTypeManual()
+	if f.Input.BundleURL != "" {
+		return []*FlutterInstallType{&manual}
+	}
+
+	fvm, asdf := f.NewFlutterInstallTypeFVM(), f.NewFlutterInstallTypeASDF()
 	installTypes := []*FlutterInstallType{}
 	switch currentVersion.installType {
 	case ASDFName:
@@ -45,23 +113,7 @@ func (f *FlutterInstaller) EnsureFlutterVersion() error {
 		installTypes = append(installTypes, &manual)
 	}
 
-	for _, installType := range installTypes {
-		if err := f.setDefaultIfInstalled(installType, requiredVersion); err == nil {
-			f.Donef("Flutter %s is already installed and set as default with %s", currentVersionString, installType.Name)
-			return nil
-		}
-		f.Debugf("Set Flutter %s default if already installed: %s", currentVersionString, err)
-	}
-
-	for _, installType := range installTypes {
-		if err := f.installAndSetDefault(installType, requiredVersion); err == nil {
-			f.Donef("Installed and set default Flutter %s with %s", currentVersionString, installType.Name)
-			return nil
-		}
-		f.Debugf("Install and set default Flutter %s: %s", currentVersionString, err)
-	}
-
-	return fmt.Errorf("installing Flutter %s: could not be installed or set as default", currentVersionString)
+	return installTypes
 }
 
 // compareVersionToCurrent compares the required Flutter version to the current version.
@@ -74,12 +126,12 @@ func (f *FlutterInstaller) compareVersionToCurrent(required flutterVersion, stri
 	}
 
 	if strict {
-		if (required.version == "" || currentVersion.version == required.version) &&
+		if (required.version == nil || currentVersion.Compare(required) == 0) &&
 			(required.channel == "" || currentVersion.channel == required.channel) {
 			return true, currentVersion
 		}
 	} else {
-		if required.version != "" && currentVersion.version == required.version {
+		if required.version != nil && currentVersion.Compare(required) == 0 {
 			return true, currentVersion
 		} else if required.channel != "" && currentVersion.channel == required.channel {
 			return true, currentVersion
@@ -88,74 +140,103 @@ func (f *FlutterInstaller) compareVersionToCurrent(required flutterVersion, stri
 	return false, currentVersion
 }
 
-func (f *FlutterInstaller) hasRelease(installType *FlutterInstallType, required flutterVersion) (bool, error) {
-	if installType.ReleasesCommand == nil {
-		return false, fmt.Errorf("no releases command defined for tool %s", installType.Name)
+// findRelease returns the release satisfying the specifier, which is installable with the install type.
+//
+// Specifiers are resolved using the official Flutter releases. Channel only specifiers are returned as is,
+// exact versions which are not official releases are only installable from git (manual install type).
+// Fork versions are only installable with FVM.
+func (f *FlutterInstaller) findRelease(installType *FlutterInstallType, required versionSpecifier) (flutterVersion, error) {
+	if fork := required.version.fork; fork != "" {
+		// Fork releases are not listed in the official releases, only FVM is able to install them.
+		if installType.Name != FVMName {
+			return flutterVersion{}, fmt.Errorf("fork %s can only be installed with FVM", fork)
+		}
+		return required.version, nil
+	}
+	if !required.isConstraint() && required.version.version == nil {
+		return required.version, nil
 	}
 
-	releasesCmd := *installType.ReleasesCommand(required)
-	out, err := releasesCmd.RunAndReturnTrimmedCombinedOutput()
+	catalogue, err := f.releaseCatalogue()
 	if err != nil {
-		return false, fmt.Errorf("list releases: %s", out)
+		if !required.isConstraint() && installType.Name == ManualName {
+			f.Debugf("Failed to list releases, installing %s from git: %s", required, err)
+			return required.version, nil
+		}
+		return flutterVersion{}, err
 	}
 
-	contains, err := f.containsVersion(out, required)
-	if err != nil {
-		return false, fmt.Errorf("check if releases contains version: %w", err)
+	version := required.version
+	if required.isConstraint() {
+		match, found := required.bestMatch(catalogue.versions())
+		if !found {
+			return flutterVersion{}, fmt.Errorf("no Flutter release matches %s", required)
+		}
+		version = match
 	}
-	if !contains {
-		return false, fmt.Errorf("not available")
+
+	release, found := catalogue.byVersion(version)
+	if !found {
+		if installType.Name == ManualName {
+			f.Debugf("%s is not an official release, installing it from git", required)
+			return version, nil
+		}
+		return flutterVersion{}, fmt.Errorf("%s is not an official Flutter release", required)
+	}
+
+	if installType.Name == FVMName {
+		// FVM caches versions by their notation, the channel is only added if it was required.
+		version.channel = required.version.channel
+	} else if version.channel == "" {
+		// asdf requires the channel of the version.
+		version.channel = release.version.channel
 	}
+	f.Debugf("Flutter %s is an official release (%s)", f.NewVersionString(version), release.hash)
 
-	f.Debugf("Flutter %s - %s is present in releases output", f.NewVersionString(required), installType.Name)
-	return true, nil
+	return version, nil
 }
 
-func (f *FlutterInstaller) hasInstalled(installType *FlutterInstallType, required flutterVersion) (bool, error) {
+// findInstalled returns the installed version satisfying the specifier.
+func (f *FlutterInstaller) findInstalled(installType *FlutterInstallType, required versionSpecifier) (flutterVersion, error) {
 	if installType.InstalledVersionsCommand == nil {
-		return false, fmt.Errorf("no installed versions command defined for tool %s", installType.Name)
+		return flutterVersion{}, fmt.Errorf("no installed versions command defined for tool %s", installType.Name)
 	}
 
 	installsCmd := *installType.InstalledVersionsCommand()
 	out, err := installsCmd.RunAndReturnTrimmedCombinedOutput()
 	if err != nil {
-		return false, fmt.Errorf("list instances: %s", out)
+		return flutterVersion{}, fmt.Errorf("list instances: %s", out)
 	}
 
-	contains, err := f.containsVersion(out, required)
+	installed, err := f.findVersion(out, required)
 	if err != nil {
-		return false, fmt.Errorf("check if installs contains version: %w", err)
-	}
-	if !contains {
-		return false, fmt.Errorf("version: %s channel: %s is not available in installed instances output: %s", required.version, required.channel, out)
+		return flutterVersion{}, fmt.Errorf("%s is not available in installed instances output: %w", required, err)
 	}
 
-	f.Debugf("Version: %s channel: %s is available in installed instances output", required.version, required.channel)
-	return true, nil
+	f.Debugf("Version: %s channel: %s is available in installed instances output", installed.versionString(), installed.channel)
+	return installed, nil
 }
 
-// containsVersion checks if the output of installed/released versions contains the required version and channel.
-func (f *FlutterInstaller) containsVersion(output string, required flutterVersion) (bool, error) {
+// findVersion returns the version satisfying the specifier from the output of installed/released versions.
+func (f *FlutterInstaller) findVersion(output string, required versionSpecifier) (flutterVersion, error) {
 	if output == "" {
-		return false, fmt.Errorf("output is empty")
+		return flutterVersion{}, fmt.Errorf("output is empty")
 	}
 
 	versions, err := NewFlutterVersionList(output)
 	if err != nil {
-		return false, fmt.Errorf("parse releases: %w", err)
+		return flutterVersion{}, fmt.Errorf("parse releases: %w", err)
 	}
 	if len(versions) == 0 {
-		return false, fmt.Errorf("no versions available in releases output: %s", output)
+		return flutterVersion{}, fmt.Errorf("no versions available in releases output: %s", output)
 	}
 
-	for _, v := range versions {
-		if (required.version == "" || required.version == v.version) &&
-			(required.channel == "" || required.channel == v.channel) {
-			return true, nil
-		}
+	version, found := required.bestMatch(versions)
+	if !found {
+		return flutterVersion{}, fmt.Errorf("output does not contain version")
 	}
 
-	return false, fmt.Errorf("output does not contain version")
+	return version, nil
 }
 
 // ensureSetupFinished makes sure that the Dart SDK is set up correctly after installation.
@@ -172,30 +253,25 @@ func (f *FlutterInstaller) ensureSetupFinished() error {
 	return nil
 }
 
-// installAndSetDefault installs the required Flutter version using the specified install type.
+// installAndSetDefault installs the Flutter version satisfying the specifier using the specified install type.
 //
-// Before installing, it checks if the version is available in releases (if applicable).
+// Before installing, it resolves the specifier to a release available for the install type.
 // After installation, it sets the version as default (if applicable).
 // It checks installation success by comparing the installed version to the required version.
-func (f *FlutterInstaller) installAndSetDefault(installType *FlutterInstallType, required flutterVersion) error {
+func (f *FlutterInstaller) installAndSetDefault(installType *FlutterInstallType, specifier versionSpecifier) (flutterVersion, error) {
 	if installType.Install == nil {
-		return fmt.Errorf("no install command defined")
+		return flutterVersion{}, fmt.Errorf("no install command defined")
 	}
 
-	f.Debugf("Installing version: %s channel: %s with %s", required.version, required.channel, installType.Name)
-
-	if installType.ReleasesCommand != nil {
-		hasRelease, err := f.hasRelease(installType, required)
-		if err != nil {
-			return fmt.Errorf("seaching for version in releases: %w", err)
-		}
-		if !hasRelease {
-			return fmt.Errorf("tool %s does not provide required version", installType.Name)
-		}
+	required, err := f.findRelease(installType, specifier)
+	if err != nil {
+		return flutterVersion{}, fmt.Errorf("seaching for version in releases: %w", err)
 	}
 
+	f.Debugf("Installing version: %s channel: %s with %s", required.versionString(), required.channel, installType.Name)
+
 	if err := installType.Install(required); err != nil {
-		return fmt.Errorf("install: %s", err)
+		return flutterVersion{}, fmt.Errorf("install: %s", err)
 	}
 	if err := f.ensureSetupFinished(); err != nil {
 		f.Debugf("ensure setup is finished: %s", err)
@@ -203,55 +279,48 @@ func (f *FlutterInstaller) installAndSetDefault(installType *FlutterInstallType,
 
 	if installType.SetDefault != nil {
 		if err := installType.SetDefault(required); err != nil {
-			return fmt.Errorf("set version default: %s", err)
+			return flutterVersion{}, fmt.Errorf("set version default: %s", err)
 		}
 		if err := f.ensureSetupFinished(); err != nil {
 			f.Debugf("ensure setup is finished: %s", err)
 		}
 	}
 
-	requiredTrimmed := flutterVersion{
-		version:     strings.TrimPrefix(required.version, "v"),
-		channel:     required.channel,
-		installType: installType.Name,
-	}
-	if installed, _ := f.compareVersionToCurrent(requiredTrimmed, false); installed {
-		return nil
+	if installed, _ := f.compareVersionToCurrent(required, false); installed {
+		return required, nil
 	}
 
-	return fmt.Errorf("version does not match required version after installing with %s", installType.Name)
+	return flutterVersion{}, fmt.Errorf("version does not match required version after installing with %s", installType.Name)
 }
 
-// setDefaultIfInstalled checks if the required Flutter version is already installed using the specified install type.
+// setDefaultIfInstalled checks if a Flutter version satisfying the specifier is already installed using the specified install type.
 //
-// If it is installed, it sets the version as default (if applicable).
+// If it is installed, it finishes its setup and sets the version as default (if applicable).
 // It checks success by comparing the installed version to the required version.
-func (f *FlutterInstaller) setDefaultIfInstalled(installType *FlutterInstallType, required flutterVersion) error {
-	hasRelease, err := f.hasInstalled(installType, required)
+func (f *FlutterInstaller) setDefaultIfInstalled(installType *FlutterInstallType, specifier versionSpecifier) (flutterVersion, error) {
+	required, err := f.findInstalled(installType, specifier)
 	if err != nil {
-		return fmt.Errorf("seaching for version in list of installed: %w", err)
+		return flutterVersion{}, fmt.Errorf("seaching for version in list of installed: %w", err)
 	}
-	if !hasRelease {
-		return fmt.Errorf("tool %s does not provide required version", installType.Name)
+
+	if installType.Setup != nil {
+		if err := installType.Setup(required); err != nil {
+			return flutterVersion{}, fmt.Errorf("set up installed version: %s", err)
+		}
 	}
 
 	if installType.SetDefault != nil {
 		if err := installType.SetDefault(required); err != nil {
-			return fmt.Errorf("set version default: %s", err)
+			return flutterVersion{}, fmt.Errorf("set version default: %s", err)
 		}
 		if err := f.ensureSetupFinished(); err != nil {
 			f.Debugf("ensure setup is finished: %s", err)
 		}
 	}
 
-	requiredTrimmed := flutterVersion{
-		version:     strings.TrimPrefix(required.version, "v"),
-		channel:     required.channel,
-		installType: installType.Name,
-	}
-	if installed, _ := f.compareVersionToCurrent(requiredTrimmed, true); installed {
-		return nil
+	if installed, _ := f.compareVersionToCurrent(required, true); installed {
+		return required, nil
 	}
 
-	return fmt.Errorf("version does not match required version after setting it default with %s", installType.Name)
+	return flutterVersion{}, fmt.Errorf("version does not match required version after setting it default with %s", installType.Name)
 }
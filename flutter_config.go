@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// flutterConfigFlagRegexp matches the flags of the `flutter config --help` output,
+// e.g. `    --[no-]enable-web    Enable Flutter for web.` or `    --jdk-dir    The Java Development Kit (JDK) installation directory.`
+var flutterConfigFlagRegexp = regexp.MustCompile(`^\s*(?:-[A-Za-z],\s*)?--(\[no-\])?([a-z0-9][a-z0-9\-]*)`)
+
+// flutterConfigSetting is an entry of the flutter_config input, e.g. `enable-web=true`.
+type flutterConfigSetting struct {
+	key   string
+	value string
+}
+
+// arg returns the `flutter config` flag of the setting: `--<key>` or `--no-<key>` for booleans, `--<key>=<value>` otherwise.
+func (s flutterConfigSetting) arg() string {
+	if enabled, err := strconv.ParseBool(s.value); err == nil {
+		if enabled {
+			return "--" + s.key
+		}
+		return "--no-" + s.key
+	}
+	return fmt.Sprintf("--%s=%s", s.key, s.value)
+}
+
+// parseFlutterConfigSettings parses the `key=value` entries of the flutter_config input, one entry per line.
+//
+// Keys can be given with or without the leading `--`, e.g. `--enable-web=true` and `enable-web=true` are the same.
+func parseFlutterConfigSettings(input string) ([]flutterConfigSetting, error) {
+	var settings []flutterConfigSetting
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(key), "--"))
+		value = strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid setting: %s, expected format: key=value", line)
+		}
+		if strings.HasPrefix(key, "no-") {
+			return nil, fmt.Errorf("invalid setting: %s, use %s=false instead", line, strings.TrimPrefix(key, "no-"))
+		}
+
+		settings = append(settings, flutterConfigSetting{key: key, value: value})
+	}
+	return settings, nil
+}
+
+// parseFlutterConfigMachine parses the current settings from the `flutter config --machine` output.
+//
+// Only the settings which are set are listed (and a few computed ones, like android-sdk),
+// so it can not be used to check which settings are supported.
+func parseFlutterConfigMachine(out string) (map[string]any, error) {
+	var settings map[string]any
+	if err := unmarshalFlutterJSON(out, &settings); err != nil {
+		return nil, fmt.Errorf("parse flutter config output: %w", err)
+	}
+	return settings, nil
+}
+
+// parseFlutterConfigFlags parses the flags supported by the installed Flutter from the `flutter config --help` output.
+//
+// The value is true for boolean settings, which have a negated (`--[no-]<key>`) form.
+func parseFlutterConfigFlags(help string) map[string]bool {
+	flags := map[string]bool{}
+	for _, line := range strings.Split(help, "\n") {
+		if match := flutterConfigFlagRegexp.FindStringSubmatch(line); match != nil {
+			flags[match[2]] = match[1] != ""
+		}
+	}
+	return flags
+}
+
+// splitSupportedSettings returns the settings supported by the installed Flutter and the unsupported ones.
+//
+// Boolean settings require a boolean value, other settings (e.g. jdk-dir) a non-boolean one.
+func splitSupportedSettings(settings []flutterConfigSetting, flags map[string]bool) ([]flutterConfigSetting, []flutterConfigSetting, error) {
+	var applicable, unsupported []flutterConfigSetting
+	for _, setting := range settings {
+		negatable, ok := flags[setting.key]
+		if !ok {
+			unsupported = append(unsupported, setting)
+			continue
+		}
+		_, err := strconv.ParseBool(setting.value)
+		if negatable && err != nil {
+			return nil, nil, fmt.Errorf("%s requires a boolean value, got: %s", setting.key, setting.value)
+		}
+		if !negatable && err == nil {
+			return nil, nil, fmt.Errorf("%s is not a boolean setting, got: %s", setting.key, setting.value)
+		}
+		applicable = append(applicable, setting)
+	}
+	return applicable, unsupported, nil
+}
+
+// applyFlutterConfig applies the settings of the flutter_config input with `flutter config`,
+// skipping (and reporting) the ones the installed Flutter version does not support.
+func (f *FlutterInstaller) applyFlutterConfig() error {
+	settings, err := parseFlutterConfigSettings(f.Input.FlutterConfig)
+	if err != nil {
+		return err
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	f.Infof("Apply Flutter config")
+
+	helpCmd := f.CmdFactory.Create("flutter", []string{"config", "--help"}, nil)
+	out, err := helpCmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return fmt.Errorf("list supported settings: %s %s", err, out)
+	}
+
+	applicable, unsupported, err := splitSupportedSettings(settings, parseFlutterConfigFlags(out))
+	if err != nil {
+		return err
+	}
+	for _, setting := range unsupported {
+		f.Warnf("Setting %s is not supported by the installed Flutter version, skipping it", setting.key)
+	}
+	if len(applicable) == 0 {
+		return nil
+	}
+
+	args := []string{"config"}
+	for _, setting := range applicable {
+		args = append(args, setting.arg())
+	}
+	configCmd := f.CmdFactory.Create("flutter", args, nil)
+	f.Donef("$ %s", configCmd.PrintableCommandArgs())
+	if out, err := configCmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s", err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_parseFlutterConfigSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "Boolean and value settings",
+			input:    "analytics=false\n--enable-web = true\n# comment\n\njdk-dir=/opt/jdk 17",
+			wantArgs: []string{"--no-analytics", "--enable-web", "--jdk-dir=/opt/jdk 17"},
+		},
+		{
+			name:    "Missing value",
+			input:   "enable-web",
+			wantErr: true,
+		},
+		{
+			name:    "Negated key",
+			input:   "no-analytics=true",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFlutterConfigSettings(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFlutterConfigSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var args []string
+			for _, setting := range got {
+				args = append(args, setting.arg())
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("parseFlutterConfigSettings() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// flutterConfigHelp is the `flutter config --help` output of Flutter 3.24.
+const flutterConfigHelp = `Configure Flutter settings.
+
+To remove a setting, configure it to an empty string.
+
+The Flutter tool anonymously reports feature usage statistics and basic crash reports to help improve Flutter tools over time. See Google's privacy policy: https://www.google.com/intl/en/policies/privacy/
+
+Usage: flutter config [arguments]
+-h, --help                                 Print this usage information.
+    --list                                 List all settings and their current values.
+    --[no-]analytics                       Enable or disable reporting anonymously tool usage statistics and crash reports.
+                                           (An alias for "--[no-]enable-analytics".)
+    --clear-ios-signing-cert               Clear the saved development certificate choice used to sign apps for iOS device deployment.
+    --android-sdk                          The Android SDK directory.
+    --android-studio-dir                   The Android Studio installation directory. If unset, flutter will search for valid installations at well-known locations.
+    --jdk-dir                              The Java Development Kit (JDK) installation directory. If unset, flutter will search for one in the following order:
+                                               1) the JDK bundled with the latest installation of Android Studio,
+                                               2) the JDK found at the directory found in the JAVA_HOME environment variable, and
+                                               3) the directory containing the java binary found in the user's path.
+    --build-dir=<out/>                     The relative path to override a projects build directory.
+    --[no-]cli-animations                  Enable the animations in the Flutter CLI.
+    --[no-]enable-web                      Enable or disable Flutter for web.
+    --[no-]enable-linux-desktop            Enable or disable support for desktop on Linux.
+    --clear-features                       Remove all configured features and restore them to the default values.
+
+Run "flutter help" to see global options.`
+
+// flutterConfigMachine is the `flutter config --machine` output on a clean machine:
+// only the computed settings are listed.
+const flutterConfigMachine = `{
+  "android-studio-dir": "/Applications/Android Studio.app/Contents",
+  "android-sdk": "/Users/vagrant/Library/Android/sdk",
+  "jdk-dir": "/Applications/Android Studio.app/Contents/jbr/Contents/Home"
+}`
+
+func Test_parseFlutterConfigMachine(t *testing.T) {
+	settings, err := parseFlutterConfigMachine("Flutter assets will be downloaded from https://storage.googleapis.com.\n" + flutterConfigMachine)
+	if err != nil {
+		t.Fatalf("parseFlutterConfigMachine() error = %v", err)
+	}
+	if got, want := settings["android-sdk"], "/Users/vagrant/Library/Android/sdk"; got != want {
+		t.Errorf("parseFlutterConfigMachine() android-sdk = %v, want %s", got, want)
+	}
+	if _, ok := settings["enable-web"]; ok {
+		t.Errorf("parseFlutterConfigMachine() lists unset setting enable-web")
+	}
+}
+
+func Test_splitSupportedSettings(t *testing.T) {
+	olderHelp := strings.ReplaceAll(flutterConfigHelp, "--[no-]cli-animations", "--[no-]enable-ios")
+
+	tests := []struct {
+		name            string
+		help            string
+		input           string
+		wantApplicable  []string
+		wantUnsupported []string
+		wantErr         bool
+	}{
+		{
+			name:           "Supported settings",
+			help:           flutterConfigHelp,
+			input:          "analytics=false\nenable-web=true\nenable-linux-desktop=true\ncli-animations=false\njdk-dir=/opt/jdk",
+			wantApplicable: []string{"analytics", "enable-web", "enable-linux-desktop", "cli-animations", "jdk-dir"},
+		},
+		{
+			name:            "Unsupported on older Flutter",
+			help:            olderHelp,
+			input:           "analytics=false\ncli-animations=false\nunknown-setting=true",
+			wantApplicable:  []string{"analytics"},
+			wantUnsupported: []string{"cli-animations", "unknown-setting"},
+		},
+		{
+			name:    "Non-boolean value of boolean setting",
+			help:    flutterConfigHelp,
+			input:   "enable-linux-desktop=yes please",
+			wantErr: true,
+		},
+		{
+			name:    "Boolean value of non-boolean setting",
+			help:    flutterConfigHelp,
+			input:   "clear-features=true",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := parseFlutterConfigSettings(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			applicable, unsupported, err := splitSupportedSettings(settings, parseFlutterConfigFlags(tt.help))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitSupportedSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			keys := func(settings []flutterConfigSetting) []string {
+				var keys []string
+				for _, setting := range settings {
+					keys = append(keys, setting.key)
+				}
+				return keys
+			}
+			if got := keys(applicable); !slices.Equal(got, tt.wantApplicable) {
+				t.Errorf("splitSupportedSettings() applicable = %v, want %v", got, tt.wantApplicable)
+			}
+			if got := keys(unsupported); !slices.Equal(got, tt.wantUnsupported) {
+				t.Errorf("splitSupportedSettings() unsupported = %v, want %v", got, tt.wantUnsupported)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const doctorReportFileName = "flutter_doctor.json"
+
+// doctorValidator is a validator result of the `flutter doctor --machine` output.
+type doctorValidator struct {
+	Name       string          `json:"name"`
+	Status     string          `json:"status"`
+	StatusInfo string          `json:"statusInfo"`
+	Messages   []doctorMessage `json:"messages"`
+}
+
+type doctorMessage struct {
+	Message string `json:"message"`
+	// Type is one of: error, hint, information.
+	Type string `json:"type"`
+}
+
+// passed checks if the validator found everything it checks installed.
+func (v doctorValidator) passed() bool {
+	return v.Status == "installed" || v.Status == "success"
+}
+
+func (v doctorValidator) String() string {
+	mark := "✓"
+	switch v.Status {
+	case "installed", "success":
+	case "partial":
+		mark = "!"
+	default:
+		mark = "✗"
+	}
+
+	line := fmt.Sprintf("[%s] %s", mark, v.Name)
+	if v.StatusInfo != "" {
+		line += " (" + v.StatusInfo + ")"
+	}
+	return line
+}
+
+// parseDoctorReport parses the validator results of the `flutter doctor --machine` output.
+func parseDoctorReport(out string) ([]doctorValidator, []byte, error) {
+	var content json.RawMessage
+	if err := unmarshalFlutterJSON(out, &content); err != nil {
+		return nil, nil, fmt.Errorf("parse flutter doctor output: %w", err)
+	}
+	var validators []doctorValidator
+	if err := json.Unmarshal(content, &validators); err != nil {
+		return nil, nil, fmt.Errorf("parse flutter doctor output: %w", err)
+	}
+	return validators, content, nil
+}
+
+// parseDoctorFailOnValidators parses the comma or newline separated list of validator names.
+func parseDoctorFailOnValidators(input string) []string {
+	return splitListInput(input)
+}
+
+// failingValidators returns the failed validators listed in the doctor_fail_on_validators input.
+//
+// Validators are matched by the prefix of their name (case insensitive),
+// for example `Android toolchain` matches `Android toolchain - develop for Android devices`.
+func failingValidators(validators []doctorValidator, failOn []string) []doctorValidator {
+	var failing []doctorValidator
+	for _, validator := range validators {
+		if validator.passed() {
+			continue
+		}
+		for _, name := range failOn {
+			if strings.HasPrefix(strings.ToLower(validator.Name), strings.ToLower(name)) {
+				failing = append(failing, validator)
+				break
+			}
+		}
+	}
+	return failing
+}
+
+// flutterDoctorReport runs `flutter doctor --machine` and returns the validator results and the raw report.
+func (f *FlutterInstaller) flutterDoctorReport() ([]doctorValidator, []byte, error) {
+	doctorCmd := f.CmdFactory.Create("flutter", []string{"doctor", "--machine"}, nil)
+	f.Donef("$ %s", doctorCmd.PrintableCommandArgs())
+	out, err := doctorCmd.RunAndReturnTrimmedOutput()
+	if err != nil {
+		f.Debugf("flutter doctor: %s", err)
+	}
+
+	validators, content, parseErr := parseDoctorReport(out)
+	if parseErr != nil {
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s", err, out)
+		}
+		return nil, nil, parseErr
+	}
+	return validators, content, nil
+}
+
+// runFlutterDoctor runs `flutter doctor --machine`, prints a summary of the validator results
+// and writes the report to the deploy directory.
+//
+// Only the failures of the validators listed in the doctor_fail_on_validators input fail the Step, others are reported as warnings.
+func (f *FlutterInstaller) runFlutterDoctor() error {
+	f.Infof("Check flutter doctor")
+
+	validators, content, err := f.flutterDoctorReport()
+	if err != nil {
+		return fmt.Errorf("check flutter doctor: %w", err)
+	}
+
+	for _, validator := range validators {
+		if validator.passed() {
+			f.Printf("%s", validator)
+			continue
+		}
+		f.Warnf("%s", validator)
+		for _, message := range validator.Messages {
+			if message.Type != "information" {
+				f.Printf("    • %s", message.Message)
+			}
+		}
+	}
+
+	if deployDir := f.EnvRepo.Get("BITRISE_DEPLOY_DIR"); deployDir != "" {
+		pth := filepath.Join(deployDir, doctorReportFileName)
+		if err := os.WriteFile(pth, content, 0644); err != nil {
+			f.Warnf("Failed to write flutter doctor report: %s", err)
+		} else {
+			f.Printf("Flutter doctor report: %s", pth)
+		}
+	}
+
+	if failing := failingValidators(validators, parseDoctorFailOnValidators(f.Input.DoctorFailOnValidators)); len(failing) > 0 {
+		var names []string
+		for _, validator := range failing {
+			names = append(names, validator.Name)
+		}
+		return fmt.Errorf("flutter doctor validators failed: %s", strings.Join(names, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+const doctorMachineOutput = `[
+  {
+    "name": "Flutter (Channel stable, 3.24.5, on macOS 14.6 23G80 darwin-arm64, locale en-US)",
+    "status": "installed",
+    "statusInfo": "Channel stable, 3.24.5",
+    "messages": [{"message": "Flutter version 3.24.5", "type": "information"}]
+  },
+  {
+    "name": "Android toolchain - develop for Android devices",
+    "status": "partial",
+    "statusInfo": "Android SDK version 34.0.0",
+    "messages": [{"message": "Android license status unknown.", "type": "error"}]
+  },
+  {
+    "name": "Chrome - develop for the web",
+    "status": "missing",
+    "messages": [{"message": "Cannot find Chrome executable", "type": "hint"}]
+  }
+]`
+
+func Test_failingValidators(t *testing.T) {
+	validators, _, err := parseDoctorReport("Welcome to Flutter!\n" + doctorMachineOutput)
+	if err != nil {
+		t.Fatalf("parseDoctorReport() error = %v", err)
+	}
+	if len(validators) != 3 || !validators[0].passed() || validators[1].passed() {
+		t.Fatalf("parseDoctorReport() = %v", validators)
+	}
+
+	tests := []struct {
+		name   string
+		failOn string
+		want   []string
+	}{
+		{
+			name:   "No validators to fail on",
+			failOn: "",
+		},
+		{
+			name:   "Failed validator",
+			failOn: "flutter, android toolchain",
+			want:   []string{"Android toolchain - develop for Android devices"},
+		},
+		{
+			name:   "Passed validator",
+			failOn: "Flutter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, validator := range failingValidators(validators, parseDoctorFailOnValidators(tt.failOn)) {
+				got = append(got, validator.Name)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("failingValidators() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
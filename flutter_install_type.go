@@ -3,7 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
-	"regexp"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/bitrise-io/go-utils/v2/command"
@@ -16,20 +17,27 @@ const (
 	FVMCacheVersionsPath = "/fvm/versions"
 	FVMCacheDefaultPath  = "/fvm/default/bin/flutter"
 	ASDFShimsPath        = "/.asdf/shims/flutter"
+	// FVMProjectSDKRelPath is the link to the SDK of the project, created by `fvm use`.
+	FVMProjectSDKRelPath = ".fvm/flutter_sdk"
+	FVMScopeGlobal       = "global"
+	FVMScopeProject      = "project"
 )
 
+// FVMScopes are the available values of the fvm_scope input.
+var FVMScopes = []string{FVMScopeGlobal, FVMScopeProject}
+
 type FlutterInstallType struct {
 	Name string
 	// IsAvailable is set to true if the tool is available.
 	IsAvailable bool
 	// InstalledVersionsCommand returns a command to list versions installed by the tool.
 	InstalledVersionsCommand func() *command.Command
-	// ReleasesCommand returns a command to list available releases, if applicable.
-	ReleasesCommand func(version flutterVersion) *command.Command
 	// Install installs a specific Flutter version.
 	Install func(version flutterVersion) error
 	// SetDefault sets a specific Flutter version as default, if applicable.
 	SetDefault func(version flutterVersion) error
+	// Setup finishes the setup of an installed version (e.g. downloads its Dart SDK), if applicable.
+	Setup func(version flutterVersion) error
 }
 
 // NewFlutterInstallTypeFVM creates a FlutterInstallType for FVM (Flutter Version Management).
@@ -38,6 +46,13 @@ type FlutterInstallType struct {
 // installing a specific version, and setting a default version based on the FVM version features.
 func (f *FlutterInstaller) NewFlutterInstallTypeFVM() FlutterInstallType {
 	available, versionOut := f.fvmIsAvailable()
+	if f.shouldInstallFVM(available, versionOut) {
+		if err := f.installFVM(); err != nil {
+			f.Warnf("Failed to install FVM: %s", err)
+		} else {
+			available, versionOut = f.fvmIsAvailable()
+		}
+	}
 	if !available {
 		return FlutterInstallType{
 			Name:        FVMName,
@@ -45,19 +60,18 @@ func (f *FlutterInstaller) NewFlutterInstallTypeFVM() FlutterInstallType {
 		}
 	}
 
-	after3_0_0, after3_1_0, after3_2_1, err := fvmParseVersionAndFeatures(versionOut)
-	if err != nil {
-		f.Warnf("Failed to investigate FVM version: %s", err)
-	}
+	features := f.fvmDetectFeatures(versionOut)
 	listArgs := []string{"list"}
-	if after3_1_0 {
-		listArgs = []string{"api", "list", "--skip-size-calculation"}
+	if features.has(fvmFeatureAPIList) {
+		listArgs = []string{"api", "list"}
+		if features.has(fvmFeatureSkipSizeCalculation) {
+			listArgs = append(listArgs, "--skip-size-calculation")
+		}
 	}
 
 	defaultArgs := []string{}
-	if after3_2_1 {
-		// FVM sometimes does not take CI environment into account,
-		// so we need to skip the input prompt, but this flag is only working great after 3.2.1.
+	if features.has(fvmFeatureSkipInput) {
+		// FVM sometimes does not take CI environment into account, so we need to skip the input prompt.
 		defaultArgs = append(defaultArgs, "--fvm-skip-input")
 	}
 
@@ -71,24 +85,25 @@ func (f *FlutterInstaller) NewFlutterInstallTypeFVM() FlutterInstallType {
 		},
 		Install: func(version flutterVersion) error {
 			args := defaultArgs
-			if after3_0_0 {
+			if features.has(fvmFeatureSetup) {
 				// FVM 3.0.0 and above requires the --setup flag to setup the version.
 				args = append(args, "--setup")
 			}
 			return f.fvmInstallVersion(version, args)
 		},
-		SetDefault: func(version flutterVersion) error {
-			return f.fvmSetDefault(version, defaultArgs)
+		Setup: func(version flutterVersion) error {
+			if !features.has(fvmFeatureAPIList) {
+				// The setup state is only reported by the fvm api.
+				return nil
+			}
+			return f.fvmEnsureSetup(version, listArgs)
 		},
-		ReleasesCommand: func(version flutterVersion) *command.Command {
-			args := append([]string{"releases"}, defaultArgs...)
-			if after3_0_0 && version.channel != "stable" && version.channel != "" {
-				args = append(args, "--channel", version.channel)
+		SetDefault: func(version flutterVersion) error {
+			args := fvmSetDefaultArgs(f.Input.FVMScope, version, defaultArgs, features.has(fvmFeatureUseSkipPubGet))
+			if f.Input.FVMScope == FVMScopeProject {
+				return f.fvmUseInProject(args)
 			}
-
-			cmd := f.CmdFactory.Create("fvm", args, nil)
-			f.Donef("$ %s", cmd.PrintableCommandArgs())
-			return &cmd
+			return f.fvmSetDefault(version, args)
 		},
 	}
 }
@@ -117,8 +132,23 @@ func (f *FlutterInstaller) fvmInstallVersion(version flutterVersion, defaultArgs
 	return nil
 }
 
-func (f *FlutterInstaller) fvmSetDefault(version flutterVersion, defaultArgs []string) error {
-	args := append([]string{"global", fvmCreateVersionString(version), "--force"}, defaultArgs...)
+// fvmSetDefaultArgs returns the arguments of the FVM command setting the version:
+// `fvm global` for the global scope, `fvm use` for the project scope.
+//
+// `fvm use` runs `flutter pub get` in the project by default, it is skipped if supported, dependencies are resolved by the pub_get input.
+func fvmSetDefaultArgs(scope string, version flutterVersion, defaultArgs []string, skipPubGet bool) []string {
+	command := "global"
+	if scope == FVMScopeProject {
+		command = "use"
+	}
+	args := []string{command, fvmCreateVersionString(version), "--force"}
+	if scope == FVMScopeProject && skipPubGet {
+		args = append(args, "--skip-pub-get")
+	}
+	return append(args, defaultArgs...)
+}
+
+func (f *FlutterInstaller) fvmSetDefault(version flutterVersion, args []string) error {
 	cmd := f.CmdFactory.Create("fvm", args, nil)
 	f.Donef("$ %s", cmd.PrintableCommandArgs())
 	_, err := cmd.RunAndReturnTrimmedCombinedOutput()
@@ -159,6 +189,32 @@ func (f *FlutterInstaller) fvmSetDefault(version flutterVersion, defaultArgs []s
 	return nil
 }
 
+// fvmUseInProject pins the version for the project only, instead of changing the global version of the machine.
+//
+// `fvm use` creates the .fvm/flutter_sdk link in the project directory, its bin directory is added to the PATH.
+// Note that it also modifies the checkout: it writes the version to .fvmrc (and the legacy .fvm/fvm_config.json),
+// adds the .fvm directory to .gitignore and updates the IDE settings (e.g. .vscode/settings.json).
+func (f *FlutterInstaller) fvmUseInProject(args []string) error {
+	projectDir, err := filepath.Abs(f.Input.ProjectLocation)
+	if err != nil {
+		return fmt.Errorf("get absolute path of project: %s", err)
+	}
+
+	cmd := f.CmdFactory.Create("fvm", args, &command.Opts{Dir: projectDir})
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("use version in project: %s %s", err, out)
+	}
+
+	binPath := filepath.Join(projectDir, FVMProjectSDKRelPath, "bin")
+	path := os.Getenv("PATH")
+	if strings.HasPrefix(path, binPath+":") {
+		return nil
+	}
+
+	return f.prependToPath(binPath)
+}
+
 func (f *FlutterInstaller) fvmIsAvailable() (bool, string) {
 	cmd := f.CmdFactory.Create("fvm", []string{"--version"}, nil)
 	f.Donef("$ %s", cmd.PrintableCommandArgs())
@@ -169,44 +225,11 @@ func (f *FlutterInstaller) fvmIsAvailable() (bool, string) {
 	}
 	f.Debugf("fvm version: %s", versionOut)
 
-	return true, ""
-}
-
-// fvmParseVersionAndFeatures parses the FVM version output and determines if it supports features introduced in specific versions.
-func fvmParseVersionAndFeatures(versionOut string) (after3_0_0, after3_1_0, after3_2_1 bool, err error) {
-	after3_0_0 = false
-	after3_2_1 = false
-	regex := regexp.MustCompile(`\d+\.\d+\.\d+`)
-
-	versionParts := strings.Split(regex.FindString(versionOut), ".")
-	if len(versionParts) < 3 {
-		err = fmt.Errorf("parse fvm version: %s: not enough version parts found", versionOut)
-		return
-	}
-
-	var major, minor, patch int
-	_, majorErr := fmt.Sscan(versionParts[0], &major)
-	_, minorErr := fmt.Sscan(versionParts[1], &minor)
-	_, patchErr := fmt.Sscan(versionParts[2], &patch)
-
-	if majorErr == nil && minorErr == nil && patchErr == nil {
-		if major < 3 {
-			return
-		}
-
-		after3_0_0 = true                                                // FVM 3.0.0 and above
-		after3_1_0 = major > 3 || minor > 0 || (minor == 1 && patch > 0) // FVM 3.1.0 and above
-		after3_2_1 = major > 3 || minor > 2 || (minor == 2 && patch > 0) // FVM 3.2.1 and above
-
-		return
-	}
-	err = fmt.Errorf("parse fvm version: %s:\nmajor:%w\nminor: %w\npatch: %w", versionOut, majorErr, minorErr, patchErr)
-
-	return
+	return true, versionOut
 }
 
 func fvmCreateVersionString(version flutterVersion) string {
-	versionString := version.version
+	versionString := version.versionString()
 	if versionString != "" {
 		if version.channel != "" {
 			versionString += "@" + version.channel
@@ -216,6 +239,9 @@ func fvmCreateVersionString(version flutterVersion) string {
 	} else {
 		versionString = "stable" // default to stable if no version or channel is specified
 	}
+	if version.fork != "" {
+		versionString = version.fork + "/" + versionString
+	}
 	return versionString
 }
 
@@ -241,11 +267,6 @@ func (f *FlutterInstaller) NewFlutterInstallTypeASDF() FlutterInstallType {
 		},
 		Install:    f.asdfInstallVersion,
 		SetDefault: f.asdfSetDefault,
-		ReleasesCommand: func(version flutterVersion) *command.Command {
-			cmd := f.CmdFactory.Create("asdf", []string{"list", "all", "flutter"}, nil)
-			f.Donef("$ %s", cmd.PrintableCommandArgs())
-			return &cmd
-		},
 	}
 }
 
@@ -289,20 +310,38 @@ func (f *FlutterInstaller) asdfSetDefault(version flutterVersion) error {
 	return nil
 }
 
+// asdfIsAvailable checks if asdf and its flutter plugin are available.
+//
+// If asdf is available without the flutter plugin, and the project's .tool-versions requests Flutter,
+// the plugin is installed if enabled by the install_asdf_plugin input.
+// The ref of an already installed plugin is only checked out if the input is enabled as well.
 func (f *FlutterInstaller) asdfIsAvailable() bool {
 	cmd := f.CmdFactory.Create("asdf", []string{"plugin-list"}, nil)
 	f.Donef("$ %s", cmd.PrintableCommandArgs())
 	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
-	if err != nil || !strings.Contains(out, "flutter") {
-		f.Warnf("asdf version manager is not available")
-		return false
+	if err == nil && strings.Contains(out, "flutter") {
+		if f.Input.InstallASDFPlugin && f.Input.ASDFPluginRef != "" {
+			if err := f.ensureASDFPlugin(true); err != nil {
+				f.Warnf("Failed to check out the ref of the asdf flutter plugin: %s", err)
+			}
+		}
+		return true
+	}
+
+	if _, lookErr := exec.LookPath("asdf"); lookErr == nil && f.Input.InstallASDFPlugin && f.projectRequiresASDFFlutter() {
+		if err := f.ensureASDFPlugin(false); err != nil {
+			f.Warnf("Failed to install asdf flutter plugin: %s", err)
+			return false
+		}
+		return true
 	}
 
-	return true
+	f.Warnf("asdf version manager is not available")
+	return false
 }
 
 func asdfCreateVersionString(version flutterVersion) string {
-	versionString := version.version
+	versionString := version.versionString()
 	if versionString == "" {
 		// Default to latest if no version is specified.
 		return "latest"
@@ -1,104 +1,9 @@
 package main
 
-import "testing"
-
-func Test_fvmParseVersionAndFeatures(t *testing.T) {
-	tests := []struct {
-		name       string
-		input      string
-		after3_0_0 bool
-		after3_2_1 bool
-		after3_1_0 bool
-		wantErr    bool
-	}{
-		{
-			name:  "Legacy case",
-			input: "2.0.6",
-		},
-		{
-			name:  "Before setup change",
-			input: "2.23.2",
-		},
-		{
-			name:       "On setup change",
-			input:      "3.0.0",
-			after3_0_0: true,
-		},
-		{
-			name:       "Before API release",
-			input:      "3.0.19",
-			after3_0_0: true,
-		},
-		{
-			name:       "On API release",
-			input:      "3.1.0",
-			after3_0_0: true,
-			after3_1_0: true,
-		},
-		{
-			name:       "Before skip input flag worked",
-			input:      "3.2.0",
-			after3_0_0: true,
-			after3_1_0: true,
-			after3_2_1: false,
-		},
-		{
-			name:       "On skip input flag working",
-			input:      "3.2.1",
-			after3_0_0: true,
-			after3_1_0: true,
-			after3_2_1: true,
-		},
-		{
-			name:       "v prefix",
-			input:      "v3.3.3",
-			after3_0_0: true,
-			after3_1_0: true,
-			after3_2_1: true,
-		},
-		{
-			name:       "Long version",
-			input:      "13.172.76",
-			after3_0_0: true,
-			after3_1_0: true,
-			after3_2_1: true,
-		},
-		{
-			name:       "fvm and flutter version",
-			input:      "fvm 3.1.6 with flutter 2.1.3",
-			after3_0_0: true,
-			after3_1_0: true,
-		},
-		{
-			name:    "Not found",
-			input:   "fvm version 3.2",
-			wantErr: true,
-		},
-		{
-			name:    "Incorrect version format",
-			input:   "fvm version 3.b.6",
-			wantErr: true,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			after3_0_0, after3_1_0, after3_2_1, err := fvmParseVersionAndFeatures(tt.input)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("fvmParseVersionAndFeatures error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if after3_0_0 != tt.after3_0_0 {
-				t.Errorf("fvmParseVersionAndFeatures useSetup = %v, want %v", after3_0_0, tt.after3_0_0)
-			}
-			if after3_2_1 != tt.after3_2_1 {
-				t.Errorf("fvmParseVersionAndFeatures useSkipInputFlag = %v, want %v", after3_2_1, tt.after3_2_1)
-			}
-			if after3_1_0 != tt.after3_1_0 {
-				t.Errorf("fvmParseVersionAndFeatures useAPI = %v, want %v", after3_1_0, tt.after3_1_0)
-			}
-		})
-	}
-}
+import (
+	"slices"
+	"testing"
+)
 
 func Test_fvmCreateVersionString(t *testing.T) {
 	tests := []struct {
@@ -109,24 +14,29 @@ func Test_fvmCreateVersionString(t *testing.T) {
 	}{
 		{
 			name:     "Version only",
-			input:    flutterVersion{version: "13.172.76", channel: ""},
+			input:    testVersion("13.172.76", "", ""),
 			expected: "13.172.76",
 		},
 		{
 			name:     "No input",
-			input:    flutterVersion{version: "", channel: ""},
+			input:    testVersion("", "", ""),
 			expected: "stable",
 		},
 		{
 			name:     "Channel only",
-			input:    flutterVersion{version: "", channel: "dev"},
+			input:    testVersion("", "dev", ""),
 			expected: "dev",
 		},
 		{
 			name:     "Version and channel",
-			input:    flutterVersion{version: "13.172.76", channel: "beta"},
+			input:    testVersion("13.172.76", "beta", ""),
 			expected: "13.172.76@beta",
 		},
+		{
+			name:     "Fork version",
+			input:    testForkVersion("mycompany", "3.22.0", ""),
+			expected: "mycompany/3.22.0",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -148,27 +58,27 @@ func Test_asdfCreateVersionString(t *testing.T) {
 	}{
 		{
 			name:     "Version only",
-			input:    flutterVersion{version: "13.172.76", channel: ""},
+			input:    testVersion("13.172.76", "", ""),
 			expected: "13.172.76-stable",
 		},
 		{
 			name:     "No input",
-			input:    flutterVersion{version: "", channel: ""},
+			input:    testVersion("", "", ""),
 			expected: "latest",
 		},
 		{
 			name:     "Channel only",
-			input:    flutterVersion{version: "", channel: "dev"},
+			input:    testVersion("", "dev", ""),
 			expected: "latest",
 		},
 		{
 			name:     "Version and channel",
-			input:    flutterVersion{version: "13.172.76", channel: "beta"},
+			input:    testVersion("13.172.76", "beta", ""),
 			expected: "13.172.76-beta",
 		},
 		{
 			name:     "Channel included in version",
-			input:    flutterVersion{version: "1.6.3-beta", channel: "stable"},
+			input:    testVersion("1.6.3-beta", "stable", ""),
 			expected: "1.6.3-beta",
 		},
 	}
@@ -182,3 +92,53 @@ func Test_asdfCreateVersionString(t *testing.T) {
 		})
 	}
 }
+
+func Test_fvmSetDefaultArgs(t *testing.T) {
+	version := testVersion("3.24.5", "", FVMName)
+	tests := []struct {
+		name       string
+		scope      string
+		skipPubGet bool
+		want       []string
+	}{
+		{
+			name:       "Global scope",
+			scope:      FVMScopeGlobal,
+			skipPubGet: true,
+			want:       []string{"global", "3.24.5", "--force", "--fvm-skip-input"},
+		},
+		{
+			name:       "Project scope",
+			scope:      FVMScopeProject,
+			skipPubGet: true,
+			want:       []string{"use", "3.24.5", "--force", "--skip-pub-get", "--fvm-skip-input"},
+		},
+		{
+			name:  "Project scope without skip pub get support",
+			scope: FVMScopeProject,
+			want:  []string{"use", "3.24.5", "--force", "--fvm-skip-input"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fvmSetDefaultArgs(tt.scope, version, []string{"--fvm-skip-input"}, tt.skipPubGet); !slices.Equal(got, tt.want) {
+				t.Errorf("fvmSetDefaultArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_currentVersionMatch(t *testing.T) {
+	specifiers, err := NewVersionSpecifiers("3.27.4\n3.24.x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := testVersion("3.24.5", "stable", FVMName)
+
+	if i, found := currentVersionMatch(specifiers, current, FVMScopeGlobal); !found || i != 1 {
+		t.Errorf("currentVersionMatch(global) = %d, %v, want entry 1", i, found)
+	}
+	if i, found := currentVersionMatch(specifiers, current, FVMScopeProject); found {
+		t.Errorf("currentVersionMatch(project) = %d, want the current version not to be checked", i)
+	}
+}
@@ -2,18 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
 
-	"github.com/bitrise-io/go-flutter/flutterproject"
-	"github.com/bitrise-io/go-flutter/fluttersdk"
-	"github.com/bitrise-io/go-utils/v2/env"
-	"github.com/bitrise-io/go-utils/v2/fileutil"
-	logv2 "github.com/bitrise-io/go-utils/v2/log"
-	"github.com/bitrise-io/go-utils/v2/pathutil"
-	"github.com/bitrise-steplib/bitrise-step-flutter-installer/tracker"
+	"github.com/Masterminds/semver/v3"
 )
 
 // Channels represents the available Flutter channels.
@@ -28,10 +24,71 @@ var Channels = []string{
 const flutterVersionRegexp = `v?([0-9]+\.[0-9]+\.[0-9]+)(?:[-\.][A-Za-z0-9\.\-]+)?`
 
 type flutterVersion struct {
-	version string
+	// version is the parsed semantic version, nil if only the channel is known.
+	version *semver.Version
 	channel string
 	// installType indicates the tool used to install the Flutter version, e.g., "fvm", "asdf" parsed from version output.
 	installType string
+	// fork is the name of the FVM fork the version is installed from, empty for the official Flutter repository.
+	fork string
+}
+
+// fvmForkVersionPathRegexp matches the FVM cache directory of a fork version: `<fvm cache>/versions/<fork>/<version>`.
+var fvmForkVersionPathRegexp = regexp.MustCompile(`/versions/([^/]+)/[^/]+/?$`)
+
+// newFlutterVersion creates a flutterVersion from a version string and channel.
+//
+// An empty version string results in a channel only version.
+func newFlutterVersion(version, channel, installType string) (flutterVersion, error) {
+	fv := flutterVersion{
+		channel:     channel,
+		installType: installType,
+	}
+	if version == "" {
+		return fv, nil
+	}
+
+	parsed, err := parseSemanticVersion(version)
+	if err != nil {
+		return flutterVersion{}, fmt.Errorf("parse version %s: %w", version, err)
+	}
+	fv.version = parsed
+
+	return fv, nil
+}
+
+// versionString returns the version in its original form (e.g. with `v` prefix), or empty string if not known.
+func (v flutterVersion) versionString() string {
+	if v.version == nil {
+		return ""
+	}
+	return v.version.Original()
+}
+
+// Equal checks if the versions, the channels and the forks are the same.
+func (v flutterVersion) Equal(other flutterVersion) bool {
+	return v.Compare(other) == 0 && v.channel == other.channel && v.fork == other.fork
+}
+
+// Compare orders the versions, a channel only version is ordered before any other version.
+func (v flutterVersion) Compare(other flutterVersion) int {
+	switch {
+	case v.version == nil && other.version == nil:
+		return 0
+	case v.version == nil:
+		return -1
+	case other.version == nil:
+		return 1
+	}
+	return compareSemanticVersions(v.version, other.version)
+}
+
+// Satisfies checks if the version satisfies the constraint, channel only versions never do.
+func (v flutterVersion) Satisfies(constraint *semver.Constraints) bool {
+	if v.version == nil {
+		return false
+	}
+	return satisfiesConstraint(v.version, constraint)
 }
 
 // NewFlutterVersion creates a new flutterVersion from the input string.
@@ -83,43 +140,142 @@ func (f *FlutterInstaller) NewFlutterVersionFromCurrent() (flutterVersion, error
 	return flutterVer, err
 }
 
-// NewFlutterVersionFromInputAndProject retrieves the Flutter version from the input or project configuration files.
-func (f *FlutterInstaller) NewFlutterVersionFromInputAndProject() (flutterVersion, error) {
-	parsedVersion, err := NewFlutterVersion(strings.TrimSpace(f.Input.Version))
+// NewVersionSpecifiersFromInputAndProject retrieves the ordered list of acceptable Flutter versions
+// from the input or project configuration files.
+//
+// The bundle URL input takes precedence, otherwise the first source in version_source_priority order
+// which declares a version is used. If no source declares a version, the latest stable release is installed.
+func (f *FlutterInstaller) NewVersionSpecifiersFromInputAndProject() ([]versionSpecifier, error) {
+	if f.Input.BundleURL != "" {
+		// The bundle determines the installed version, version and channel inputs are ignored.
+		parsedVersion, err := NewFlutterVersion(f.Input.BundleURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse version from bundle URL: %w", err)
+		}
+		f.Debugf("Using bundle URL input, ignoring version (%s) and channel (%s) inputs", f.Input.Version, f.Input.Channel)
+		return []versionSpecifier{newVersionSpecifierFromVersion(parsedVersion, f.Input.BundleURL)}, nil
+	}
+
+	priority, err := parseVersionSourcePriority(f.Input.VersionSourcePriority)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'version_source_priority' input: %w", err)
+	}
+
+	specifiers, err := f.versionSpecifiersFromInput()
 	if err != nil {
-		f.Debugf("parse version from input: %w", err)
-	} else if parsedVersion.version != "" || parsedVersion.channel != "" {
-		return parsedVersion, nil
+		return nil, fmt.Errorf("invalid 'version' input: %w", err)
+	}
+
+	sources, sdkVersions, parseErr := f.parseProjectConfigFiles(f.Input.ProjectLocation)
+	if errors.Is(parseErr, errFVMFlavorNotFound) {
+		return nil, fmt.Errorf("invalid 'fvm_flavor' input: %w", parseErr)
+	} else if parseErr != nil {
+		f.Debugf("parse version from project config files: %s", parseErr)
+	}
+	if len(specifiers) > 0 {
+		sources = append(sources, versionSource{name: InputSourceName, specifier: specifiers[0]})
+	}
+	// The workspace constraints and the .metadata revision are only resolved (which may require the release catalogue)
+	// if they could be selected.
+	if versionSourceSelectable(sources, priority, "workspace") {
+		if workspaceSource, ok, err := f.workspaceVersionSource(f.Input.ProjectLocation); err != nil {
+			f.Warnf("Failed to resolve Flutter version of the workspace: %s", err)
+		} else if ok {
+			sources = append(sources, workspaceSource)
+		}
+	}
+	if versionSourceSelectable(sources, priority, "metadata") {
+		if metadataSource, ok, err := f.metadataVersionSource(f.Input.ProjectLocation); err != nil {
+			f.Debugf("read Flutter version from %s: %s", MetadataSourceName, err)
+		} else if ok {
+			sources = append(sources, metadataSource)
+		}
+	}
+	if parseErr == nil {
+		trackSDKVersions(sdkVersions, sources)
+	}
+	sources = prioritizeVersionSources(sources, priority)
+
+	// A single input entry is checked against the project files, fallback lists are intentionally permissive.
+	checkedSources := sources
+	selected := 0
+	if len(specifiers) > 1 {
+		checkedSources = slices.DeleteFunc(slices.Clone(sources), func(s versionSource) bool { return s.name == InputSourceName })
+		if len(sources) > 0 && sources[0].name == InputSourceName {
+			selected = -1
+		}
+	}
+	if err := f.checkVersionSources(checkedSources, selected); err != nil {
+		return nil, err
 	}
 
-	parsedVersion, err = parseProjectConfigFiles()
+	if len(sources) == 0 {
+		f.Warnf("No Flutter version is specified in the inputs or project files, installing the latest stable version.")
+		return []versionSpecifier{newVersionSpecifierFromVersion(flutterVersion{channel: "stable"}, "stable")}, nil
+	}
+	if sources[0].name == InputSourceName {
+		return specifiers, nil
+	}
+
+	return []versionSpecifier{sources[0].specifier}, nil
+}
+
+// versionSpecifiersFromInput parses the version and channel inputs.
+//
+// If the channel input is set, it applies to the exact versions without a channel.
+func (f *FlutterInstaller) versionSpecifiersFromInput() ([]versionSpecifier, error) {
+	specifiers, err := NewVersionSpecifiers(f.Input.Version)
 	if err != nil {
-		f.Debugf("parse version from project config files: %w", err)
-	} else if parsedVersion.version != "" || parsedVersion.channel != "" {
-		return parsedVersion, nil
+		return nil, err
+	}
+	if f.Input.Channel != "" {
+		if len(specifiers) == 0 {
+			specifiers = []versionSpecifier{newVersionSpecifierFromVersion(flutterVersion{channel: f.Input.Channel}, f.Input.Channel)}
+		}
+		for i, specifier := range specifiers {
+			if !specifier.isConstraint() && specifier.version.channel == "" {
+				specifiers[i].version.channel = f.Input.Channel
+				specifiers[i].raw = f.NewVersionString(specifiers[i].version)
+			}
+		}
 	}
 
-	return flutterVersion{}, fmt.Errorf("no Flutter version specified in the configuration or project files")
+	return specifiers, nil
 }
 
 // NewVersionString formats the flutterVersion into a human-readable string.
 func (f *FlutterInstaller) NewVersionString(version flutterVersion) string {
-	versionString := version.version
+	forkPrefix := ""
+	if version.fork != "" {
+		forkPrefix = version.fork + "/"
+	}
+
+	versionString := version.versionString()
 
 	if versionString != "" {
 		if version.channel != "" {
 			versionString += "(" + version.channel + ")"
 		}
-		return versionString
+		return forkPrefix + versionString
 	}
 
 	if version.channel != "" {
-		return version.channel
+		return forkPrefix + version.channel
 	}
 
 	return "unknown"
 }
 
+// unmarshalFlutterJSON parses the JSON output of a Flutter command run with the --machine flag.
+func unmarshalFlutterJSON(out string, v any) error {
+	// The JSON can be preceded by messages, like the welcome banner.
+	start := strings.IndexAny(out, "{[")
+	if start == -1 {
+		return fmt.Errorf("no JSON in output: %s", out)
+	}
+	return json.Unmarshal([]byte(out[start:]), v)
+}
+
 func parseVersionsFromJson(input string, singleResult bool) ([]flutterVersion, error) {
 	input = strings.TrimSpace(input)
 	if input == "" {
@@ -204,11 +360,15 @@ func parseVersionFromJsonMap(data map[string]any) (flutterVersion, error) {
 
 	channelKeys := []string{"channel", "releaseFromChannel"}
 	channel := ""
+	channelUnknown := false
 	for _, key := range channelKeys {
 		channel = extractChannel(&data, key)
 		if channel != "" {
 			break
 		}
+		if c, ok := data[key].(string); ok && strings.EqualFold(strings.TrimSpace(c), "unknown") {
+			channelUnknown = true
+		}
 	}
 	if channel == "" {
 		// Special case: if type == "channel", check "name"
@@ -229,19 +389,25 @@ func parseVersionFromJsonMap(data map[string]any) (flutterVersion, error) {
 		installType = it
 	}
 
-	return flutterVersion{
-		version:     version,
-		channel:     channel,
-		installType: installType,
-	}, nil
+	fv, err := newFlutterVersion(version, channel, installType)
+	if err != nil {
+		return flutterVersion{}, err
+	}
+	fv.fork = extractFork(&data)
+	if fv.channel == "" && channelUnknown {
+		// Installed from an archive, the channel is not tracked by the SDK.
+		fv.channel = inferChannel(fv.version)
+	}
+
+	return fv, nil
 }
 
 func extractVersion(data *map[string]any, key string) string {
 	if v, ok := (*data)[key].(string); ok {
 		v = strings.TrimSpace(v)
 		v = strings.ToLower(v)
-		if v != "" && regexp.MustCompile(flutterVersionRegexp).MatchString(v) {
-			return v
+		if v != "" {
+			return regexp.MustCompile(flutterVersionRegexp).FindString(v)
 		}
 	}
 	return ""
@@ -258,6 +424,24 @@ func extractChannel(data *map[string]any, key string) string {
 	return ""
 }
 
+// extractFork returns the FVM fork name from the `fork/version` name of fvm api output,
+// or from the FVM cache path of the SDK.
+func extractFork(data *map[string]any) string {
+	if name, ok := (*data)["name"].(string); ok {
+		if fork, _, found := strings.Cut(name, "/"); found {
+			return fork
+		}
+	}
+	for _, key := range []string{"flutterRoot", "directory"} {
+		if pth, ok := (*data)[key].(string); ok {
+			if match := fvmForkVersionPathRegexp.FindStringSubmatch(filepath.ToSlash(pth)); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
+
 func extractRoot(data *map[string]any, key string) string {
 	if m, ok := (*data)[key].(string); ok {
 		if strings.Contains(m, FVMName) {
@@ -306,11 +490,11 @@ func parseVersionFromStringLines(input string, singleResult bool) ([]flutterVers
 			continue
 		}
 
-		versions = append(versions, flutterVersion{
-			version:     currentVersion,
-			channel:     currentChannel,
-			installType: defaultManager,
-		})
+		fv, err := newFlutterVersion(currentVersion, currentChannel, defaultManager)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, fv)
 		if singleResult {
 			return versions, nil
 		}
@@ -322,66 +506,3 @@ func parseVersionFromStringLines(input string, singleResult bool) ([]flutterVers
 
 	return versions, nil
 }
-
-// parseProjectConfigFiles retrieves the Flutter version from the project configuration files.
-//
-// It checks for versions in pubspec.yaml, fvm, and asdf configurations.
-func parseProjectConfigFiles() (flutterVersion, error) {
-	proj, err := flutterproject.New("./", fileutil.NewFileManager(), pathutil.NewPathChecker(), fluttersdk.NewSDKVersionFinder())
-	if err != nil {
-		return flutterVersion{}, fmt.Errorf("open project: %s", err)
-	}
-	sdkVersions, err := proj.FlutterAndDartSDKVersions()
-	if err != nil {
-		return flutterVersion{}, fmt.Errorf("get Flutter and Dart SDK versions: %s", err)
-	}
-	stepTracker := tracker.NewStepTracker(logv2.NewLogger(), env.NewRepository())
-	stepTracker.LogSDKVersions(sdkVersions)
-	defer stepTracker.Wait()
-
-	versionRegexp := regexp.MustCompile(flutterVersionRegexp)
-
-	if fvmVersion := sdkVersions.FVMFlutterVersion; fvmVersion != nil {
-		fvmVersionString := fvmVersion.String()
-		channel := sdkVersions.FVMFlutterChannel
-		if (fvmVersionString != "" && versionRegexp.MatchString(fvmVersionString)) ||
-			channel != "" {
-			return flutterVersion{
-				version:     fvmVersion.String(),
-				channel:     channel,
-				installType: FVMName,
-			}, nil
-		}
-	}
-
-	if sdkVersions.ASDFFlutterVersion != nil {
-		asdfVersionString := sdkVersions.ASDFFlutterVersion.String()
-		channel := sdkVersions.ASDFFlutterChannel
-		if (asdfVersionString != "" && versionRegexp.MatchString(asdfVersionString)) ||
-			channel != "" {
-			return flutterVersion{
-				version:     sdkVersions.ASDFFlutterVersion.String(),
-				channel:     channel,
-				installType: ASDFName,
-			}, nil
-		}
-	}
-
-	if pubLock := sdkVersions.PubspecLockFlutterVersion; pubLock != nil {
-		if version := pubLock.String(); version != "" && versionRegexp.MatchString(version) {
-			return flutterVersion{
-				version: version,
-			}, nil
-		}
-	}
-
-	if pubSpec := sdkVersions.PubspecFlutterVersion; pubSpec != nil {
-		if version := pubSpec.String(); version != "" && versionRegexp.MatchString(version) {
-			return flutterVersion{
-				version: sdkVersions.PubspecFlutterVersion.String(),
-			}, nil
-		}
-	}
-
-	return flutterVersion{}, fmt.Errorf("no Flutter version found in the project files")
-}
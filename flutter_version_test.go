@@ -4,6 +4,27 @@ import (
 	"testing"
 )
 
+// testVersion creates a flutterVersion from valid version strings.
+func testVersion(version, channel, installType string) flutterVersion {
+	v, err := newFlutterVersion(version, channel, installType)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// testForkVersion creates an FVM fork version.
+func testForkVersion(fork, version, channel string) flutterVersion {
+	v := testVersion(version, channel, FVMName)
+	v.fork = fork
+	return v
+}
+
+// versionsEqual compares versions in their original form, including the install type and fork.
+func versionsEqual(a, b flutterVersion) bool {
+	return a.versionString() == b.versionString() && a.channel == b.channel && a.installType == b.installType && a.fork == b.fork
+}
+
 const versionMachineOut = `
 {
   "frameworkVersion": "3.33.0-0.2.pre",
@@ -46,6 +67,36 @@ const versionMachineOutUnknownChannel = `
 }
 `
 
+const fvmApiListForkOutput = `
+{
+  "size": "1.32 GB",
+  "versions": [
+    {
+      "name": "mycompany/3.22.0",
+      "directory": "/Users/vagrant/fvm/versions/mycompany/3.22.0",
+      "releaseFromChannel": null,
+      "type": "release",
+      "binPath": "/Users/vagrant/fvm/versions/mycompany/3.22.0/bin",
+      "flutterExec": "/Users/vagrant/fvm/versions/mycompany/3.22.0/bin/flutter",
+      "flutterSdkVersion": "3.22.0",
+      "dartSdkVersion": "3.4.0",
+      "isSetup": true
+    },
+    {
+      "name": "3.22.0",
+      "directory": "/Users/vagrant/fvm/versions/3.22.0",
+      "releaseFromChannel": null,
+      "type": "release",
+      "binPath": "/Users/vagrant/fvm/versions/3.22.0/bin",
+      "flutterExec": "/Users/vagrant/fvm/versions/3.22.0/bin/flutter",
+      "flutterSdkVersion": "3.22.0",
+      "dartSdkVersion": "3.4.0",
+      "isSetup": true
+    }
+  ]
+}
+`
+
 const fvmApiListOutput = `
 {
   "size": "2.58 GB",
@@ -220,22 +271,22 @@ func Test_NewFlutterVersion(t *testing.T) {
 		{
 			name:  "normal case",
 			input: versionMachineOut,
-			want:  flutterVersion{version: "3.33.0-0.2.pre", channel: "beta", installType: FVMName},
+			want:  testVersion("3.33.0-0.2.pre", "beta", FVMName),
 		},
 		{
 			name:  "incomplete version",
 			input: versionMachineOutIncomplete,
-			want:  flutterVersion{version: "1.6.3", channel: "beta"},
+			want:  testVersion("1.6.3", "beta", ""),
 		},
 		{
-			name:  "unknown channel",
+			name:  "unknown channel inferred from version",
 			input: versionMachineOutUnknownChannel,
-			want:  flutterVersion{version: "2.11.0-0.1.pre"},
+			want:  testVersion("2.11.0-0.1.pre", "beta", ""),
 		},
 		{
 			name:  "build flutter",
 			input: versionOutWithBuild,
-			want:  flutterVersion{version: "1.7.1-pre.49", channel: "master"},
+			want:  testVersion("1.7.1-pre.49", "master", ""),
 		},
 		{
 			name:    "not found",
@@ -246,27 +297,27 @@ func Test_NewFlutterVersion(t *testing.T) {
 		{
 			name:  "bundle URL",
 			input: bundleURL,
-			want:  flutterVersion{version: "v1.6.3", channel: "beta"},
+			want:  testVersion("v1.6.3", "beta", ""),
 		},
 		{
 			name:  "asterisk in version",
 			input: "*3.32.5-stable",
-			want:  flutterVersion{version: "3.32.5", channel: "stable"},
+			want:  testVersion("3.32.5", "stable", ""),
 		},
 		{
 			name:  "valid version and channel",
 			input: "3.33.0-0.2.pre beta",
-			want:  flutterVersion{version: "3.33.0-0.2.pre", channel: "beta"},
+			want:  testVersion("3.33.0-0.2.pre", "beta", ""),
 		},
 		{
 			name:  "valid channel and version",
 			input: "dev 3.33.0-0.2.pre",
-			want:  flutterVersion{version: "3.33.0-0.2.pre", channel: "dev"},
+			want:  testVersion("3.33.0-0.2.pre", "dev", ""),
 		},
 		{
 			name:  "valid version and channel (different order)",
 			input: "beta 3.33.0-0.2.pre",
-			want:  flutterVersion{version: "3.33.0-0.2.pre", channel: "beta"},
+			want:  testVersion("3.33.0-0.2.pre", "beta", ""),
 		},
 		{
 			name:  "missing version",
@@ -281,7 +332,7 @@ func Test_NewFlutterVersion(t *testing.T) {
 		{
 			name:  "missing channel",
 			input: "3.33.0-0.2.pre",
-			want:  flutterVersion{version: "3.33.0-0.2.pre"},
+			want:  testVersion("3.33.0-0.2.pre", "", ""),
 		},
 		{
 			name:    "invalid input",
@@ -297,7 +348,7 @@ func Test_NewFlutterVersion(t *testing.T) {
 				t.Errorf("NewFlutterVersion error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
+			if !versionsEqual(got, tt.want) {
 				t.Errorf("NewFlutterVersion = %v, want %v", got, tt.want)
 			}
 		})
@@ -316,106 +367,50 @@ func Test_NewFlutterVersionList(t *testing.T) {
 			name:  "api list output with multiple versions",
 			input: fvmApiListOutput,
 			want: []flutterVersion{
-				{
-					version:     "3.32.5",
-					channel:     "stable",
-					installType: FVMName,
-				},
-				{
-					version:     "",
-					channel:     "dev",
-					installType: FVMName,
-				},
-				{
-					version:     "3.33.0-0.2.pre",
-					channel:     "",
-					installType: FVMName,
-				},
-				{
-					version:     "3.32.0",
-					channel:     "stable",
-					installType: FVMName,
-				},
-				{
-					version:     "3.10.6",
-					channel:     "",
-					installType: FVMName,
-				},
+				testVersion("3.32.5", "stable", FVMName),
+				testVersion("", "dev", FVMName),
+				testVersion("3.33.0-0.2.pre", "", FVMName),
+				testVersion("3.32.0", "stable", FVMName),
+				testVersion("3.10.6", "", FVMName),
+			},
+		},
+		{
+			name:  "api list output with fork version",
+			input: fvmApiListForkOutput,
+			want: []flutterVersion{
+				testForkVersion("mycompany", "3.22.0", ""),
+				testVersion("3.22.0", "", FVMName),
 			},
 		},
 		{
 			name:  "list output with multiple versions",
 			input: fvmListOutput,
 			want: []flutterVersion{
-				{
-					version:     "3.32.5",
-					channel:     "stable",
-					installType: FVMName,
-				},
-				{
-					version:     "",
-					channel:     "dev",
-					installType: FVMName,
-				},
-				{
-					version:     "3.33.0-0.2.pre",
-					channel:     "beta",
-					installType: FVMName,
-				},
-				{
-					version:     "3.32.0",
-					channel:     "stable",
-					installType: FVMName,
-				},
-				{
-					version:     "3.10.6",
-					channel:     "",
-					installType: FVMName,
-				},
+				testVersion("3.32.5", "stable", FVMName),
+				testVersion("", "dev", FVMName),
+				testVersion("3.33.0-0.2.pre", "beta", FVMName),
+				testVersion("3.32.0", "stable", FVMName),
+				testVersion("3.10.6", "", FVMName),
 			},
 		},
 		{
 			name:  "list releases",
 			input: fvmReleasesOutput,
 			want: []flutterVersion{
-				{
-					version: "v1.2.1",
-					channel: "stable",
-				},
-				{
-					version: "v1.5.4-hotfix.2",
-					channel: "stable",
-				},
-				{
-					version: "1.22.6",
-					channel: "stable",
-				},
-				{
-					version: "3.32.5",
-					channel: "stable",
-				},
-				{
-					version: "3.32.5",
-					channel: "stable",
-				},
+				testVersion("v1.2.1", "stable", ""),
+				testVersion("v1.5.4-hotfix.2", "stable", ""),
+				testVersion("1.22.6", "stable", ""),
+				testVersion("3.32.5", "stable", ""),
+				testVersion("3.32.5", "stable", ""),
 			},
 		},
 		{
 			name:  "dev channel relases",
 			input: fvmReleasesOutputDevChannel,
 			want: []flutterVersion{
-				{
-					version: "v0.1.6",
-					channel: "dev",
-				},
-				{
-					version: "v1.4.6-hotfix.1",
-					channel: "dev",
-				},
-				{
-					version: "2.11.0-0.1.pre",
-					channel: "dev",
-				},
+				testVersion("v0.1.6", "dev", ""),
+				testVersion("v1.4.6-hotfix.1", "dev", ""),
+				testVersion("2.11.0-0.1.pre", "dev", ""),
 			},
 		},
 	}
@@ -432,7 +427,7 @@ func Test_NewFlutterVersionList(t *testing.T) {
 				return
 			}
 			for i, v := range got {
-				if v != tt.want[i] {
+				if !versionsEqual(v, tt.want[i]) {
 					t.Errorf("NewFlutterVersionList = %v, want %v", v, tt.want[i])
 				}
 			}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// fvmAPIList is the output of `fvm api list`.
+type fvmAPIList struct {
+	Versions []fvmCachedVersion `json:"versions"`
+}
+
+// fvmCachedVersion is a Flutter SDK in the FVM cache.
+type fvmCachedVersion struct {
+	// Name is the FVM version notation, e.g. `3.22.0`, `3.22.0@beta`, `stable`, `mycompany/3.22.0`.
+	Name      string `json:"name"`
+	Directory string `json:"directory"`
+	// FlutterExec is the path of the flutter executable of the SDK.
+	FlutterExec string `json:"flutterExec"`
+	// FlutterSdkVersion is null if the SDK is not set up.
+	FlutterSdkVersion string `json:"flutterSdkVersion"`
+	// IsSetup is false if the SDK is cloned, but its Dart SDK was never downloaded.
+	IsSetup bool `json:"isSetup"`
+}
+
+// flutterVersion returns the version of the cached SDK, the SDK version is used for channels if it is known.
+//
+// Releases cached without a channel (e.g. `3.22.0`) are installed from the channel of the release,
+// which is inferred from the version form, so they are only matched by a version required on that channel.
+func (v fvmCachedVersion) flutterVersion() (flutterVersion, error) {
+	version, err := parseFVMVersionString(v.Name)
+	if err != nil {
+		return flutterVersion{}, err
+	}
+	if version.version != nil && version.channel == "" {
+		version.channel = inferChannel(version.version)
+	}
+	if version.version == nil && v.FlutterSdkVersion != "" {
+		sdkVersion, err := newFlutterVersion(v.FlutterSdkVersion, version.channel, FVMName)
+		if err != nil {
+			return flutterVersion{}, err
+		}
+		version.version = sdkVersion.version
+	}
+	return version, nil
+}
+
+func parseFVMAPIList(output string) ([]fvmCachedVersion, error) {
+	var list fvmAPIList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("parse fvm api list output: %w", err)
+	}
+	return list.Versions, nil
+}
+
+// findFVMCachedVersion returns the cached SDK of the version.
+//
+// FVM caches the versions by their notation, so the entry of the same notation is preferred,
+// otherwise the version and channel are compared the same way as the version specifiers.
+func findFVMCachedVersion(cachedVersions []fvmCachedVersion, version flutterVersion) (fvmCachedVersion, bool) {
+	name := fvmCreateVersionString(version)
+	for _, cached := range cachedVersions {
+		if cached.Name == name {
+			return cached, true
+		}
+	}
+
+	specifier := newVersionSpecifierFromVersion(version, name)
+	for _, cached := range cachedVersions {
+		cachedVersion, err := cached.flutterVersion()
+		if err != nil {
+			continue
+		}
+		if specifier.matches(cachedVersion) {
+			return cached, true
+		}
+	}
+	return fvmCachedVersion{}, false
+}
+
+// fvmEnsureSetup finishes the setup of a cached version, if its Dart SDK was never downloaded.
+//
+// Otherwise the setup would happen in the post-install check, or in the first build Step.
+func (f *FlutterInstaller) fvmEnsureSetup(version flutterVersion, listArgs []string) error {
+	cmd := f.CmdFactory.Create("fvm", listArgs, nil)
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return fmt.Errorf("list cached versions: %s %s", err, out)
+	}
+
+	cachedVersions, err := parseFVMAPIList(out)
+	if err != nil {
+		return err
+	}
+	cached, ok := findFVMCachedVersion(cachedVersions, version)
+	if !ok {
+		return fmt.Errorf("%s is not in the FVM cache", fvmCreateVersionString(version))
+	}
+	if cached.IsSetup {
+		f.Debugf("Flutter %s is set up in %s", cached.Name, cached.Directory)
+		return nil
+	}
+
+	f.Printf("Flutter %s is cached, but not set up, setting it up", cached.Name)
+	flutterExec := cached.FlutterExec
+	if flutterExec == "" {
+		// Fall back to the standard location of the executable if it is not listed.
+		flutterExec = filepath.Join(cached.Directory, "bin", "flutter")
+	}
+	setupCmd := f.CmdFactory.Create(flutterExec, []string{"--version"}, nil)
+	f.Donef("$ %s", setupCmd.PrintableCommandArgs())
+	if out, err := setupCmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("set up %s: %s %s", cached.Name, err, out)
+	}
+
+	return nil
+}
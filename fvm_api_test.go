@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func Test_findFVMCachedVersion(t *testing.T) {
+	cachedVersions, err := parseFVMAPIList(fvmApiListOutput)
+	if err != nil {
+		t.Fatalf("parseFVMAPIList() error = %v", err)
+	}
+	if len(cachedVersions) != 5 {
+		t.Fatalf("parseFVMAPIList() = %d versions, want 5", len(cachedVersions))
+	}
+
+	tests := []struct {
+		name        string
+		version     flutterVersion
+		want        string
+		wantIsSetup bool
+		wantFound   bool
+	}{
+		{
+			name:        "Channel by SDK version",
+			version:     testVersion("3.32.5", "", ""),
+			want:        "stable",
+			wantIsSetup: true,
+			wantFound:   true,
+		},
+		{
+			name:        "Version with channel",
+			version:     testVersion("3.32.0", "stable", ""),
+			want:        "3.32.0@stable",
+			wantIsSetup: true,
+			wantFound:   true,
+		},
+		{
+			name:      "Not set up version",
+			version:   testVersion("3.10.6", "", ""),
+			want:      "3.10.6",
+			wantFound: true,
+		},
+		{
+			name:      "Not set up channel",
+			version:   testVersion("", "dev", ""),
+			want:      "dev",
+			wantFound: true,
+		},
+		{
+			name:      "Release cached without channel",
+			version:   testVersion("3.10.6", "stable", ""),
+			want:      "3.10.6",
+			wantFound: true,
+		},
+		{
+			name:    "Release cached on another channel",
+			version: testVersion("3.10.6", "beta", ""),
+		},
+		{
+			name:    "Version cached on another channel",
+			version: testVersion("3.32.0", "beta", ""),
+		},
+		{
+			name:    "Not cached",
+			version: testVersion("3.24.5", "", ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := findFVMCachedVersion(cachedVersions, tt.version)
+			if found != tt.wantFound {
+				t.Fatalf("findFVMCachedVersion() found = %v, want %v", found, tt.wantFound)
+			}
+			if got.Name != tt.want || got.IsSetup != tt.wantIsSetup {
+				t.Errorf("findFVMCachedVersion() = %s (isSetup: %v), want %s (isSetup: %v)", got.Name, got.IsSetup, tt.want, tt.wantIsSetup)
+			}
+			if found && got.FlutterExec != got.Directory+"/bin/flutter" {
+				t.Errorf("findFVMCachedVersion() flutterExec = %s, directory = %s", got.FlutterExec, got.Directory)
+			}
+		})
+	}
+}
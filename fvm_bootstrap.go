@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bitrise-io/go-steputils/tools"
+)
+
+const (
+	// FVMPinnedVersion is the FVM release installed if the project requires FVM, but it is not available.
+	FVMPinnedVersion = "3.2.1"
+	// FVMMinimumVersion is the oldest FVM version supported, older versions are upgraded if FVM installation is enabled.
+	FVMMinimumVersion   = "3.0.0"
+	fvmReleaseURLFormat = "https://github.com/leoafarias/fvm/releases/download/%s/fvm-%s-%s-%s.tar.gz"
+	fvmInstallDirName   = ".fvm_flutter"
+	fvmConfigRelPath    = ".fvm/fvm_config.json"
+)
+
+// fvmReleaseURL returns the URL of the standalone FVM release archive built for the OS and architecture.
+func fvmReleaseURL(version, goos, goarch string) (string, error) {
+	platform := ""
+	switch goos {
+	case "darwin":
+		platform = "macos"
+	case "linux":
+		platform = "linux"
+	default:
+		return "", fmt.Errorf("no standalone FVM release for %s", goos)
+	}
+
+	architecture := ""
+	switch goarch {
+	case "amd64":
+		architecture = "x64"
+	case "arm64":
+		architecture = "arm64"
+	default:
+		return "", fmt.Errorf("no standalone FVM release for %s %s", goos, goarch)
+	}
+
+	return fmt.Sprintf(fvmReleaseURLFormat, version, version, platform, architecture), nil
+}
+
+// projectRequiresFVM checks if the project has an FVM config file, or FVM specific inputs are set.
+func (f *FlutterInstaller) projectRequiresFVM() bool {
+	if f.Input.FVMScope == FVMScopeProject || f.Input.FVMFlavor != "" {
+		return true
+	}
+	for _, relPath := range []string{fvmrcRelPath, fvmConfigRelPath} {
+		if _, err := os.Stat(filepath.Join(f.Input.ProjectLocation, relPath)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldInstallFVM checks if FVM needs to be installed or upgraded (below 3.0.0) for the project.
+func (f *FlutterInstaller) shouldInstallFVM(available bool, versionOut string) bool {
+	if !f.Input.InstallFVM || !f.projectRequiresFVM() {
+		return false
+	}
+	if !available {
+		return true
+	}
+
+	version, err := parseFVMVersion(versionOut)
+	if err != nil {
+		// Do not replace an installation of unknown version.
+		f.Debugf("Failed to investigate FVM version: %s", err)
+		return false
+	}
+	if version.LessThan(semver.MustParse(FVMMinimumVersion)) {
+		f.Warnf("FVM %s is outdated, upgrading to %s", versionOut, FVMPinnedVersion)
+		return true
+	}
+	return false
+}
+
+// installFVM installs the pinned FVM release from the standalone release archive,
+// or if it is not available, with `dart pub global activate`.
+func (f *FlutterInstaller) installFVM() error {
+	f.Infof("Installing FVM %s", FVMPinnedVersion)
+
+	if err := f.installFVMFromRelease(); err != nil {
+		f.Warnf("Failed to install FVM from the release archive: %s", err)
+		if pubErr := f.installFVMWithPub(); pubErr != nil {
+			return fmt.Errorf("install from release archive: %s, install with pub: %w", err, pubErr)
+		}
+	}
+
+	cmd := f.CmdFactory.Create("fvm", []string{"--version"}, nil)
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	versionOut, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return fmt.Errorf("check installed FVM version: %s %s", err, versionOut)
+	}
+	version, err := parseFVMVersion(versionOut)
+	if err != nil {
+		return fmt.Errorf("verify installed FVM: %w", err)
+	}
+	if version.LessThan(semver.MustParse(FVMMinimumVersion)) {
+		return fmt.Errorf("installed FVM version is still outdated: %s", versionOut)
+	}
+
+	f.Donef("Installed FVM %s", versionOut)
+	return nil
+}
+
+func (f *FlutterInstaller) installFVMFromRelease() error {
+	releaseURL, err := fvmReleaseURL(FVMPinnedVersion, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	f.Printf("Downloading %s", releaseURL)
+	archivePth, err := f.downloadBundle(releaseURL)
+	if err != nil {
+		return fmt.Errorf("download: %s", err)
+	}
+
+	installDir := filepath.Join(os.Getenv("HOME"), fvmInstallDirName)
+	if err := os.RemoveAll(installDir); err != nil {
+		return fmt.Errorf("remove path(%s): %s", installDir, err)
+	}
+	if err := os.MkdirAll(installDir, 0770); err != nil {
+		return fmt.Errorf("create folder (%s): %s", installDir, err)
+	}
+
+	tarCmd := f.CmdFactory.Create("tar", []string{"--no-same-owner", "-xzf", archivePth, "-C", installDir}, nil)
+	f.Donef("$ %s", tarCmd.PrintableCommandArgs())
+	if out, err := tarCmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("tar command: %s %s", err, out)
+	}
+
+	// The archive contains the fvm executable in an fvm directory.
+	return f.prependToPath(filepath.Join(installDir, "fvm"))
+}
+
+// installFVMWithPub activates the pinned FVM release with the Dart SDK available on the PATH.
+//
+// FVM is installed before Flutter, so the pre-installed Dart SDK is used.
+func (f *FlutterInstaller) installFVMWithPub() error {
+	dart, err := exec.LookPath("dart")
+	if err != nil {
+		return fmt.Errorf("dart is not available: %w", err)
+	}
+
+	pubCacheDir := f.pubCacheDir()
+	if err := f.activateDartGlobalTool(dart, pubCacheDir, dartGlobalTool{name: "fvm", version: FVMPinnedVersion}); err != nil {
+		return err
+	}
+
+	return f.addPubCacheBinToPath(pubCacheDir)
+}
+
+// prependToPath adds the directory to the PATH of the Step and the subsequent Steps.
+func (f *FlutterInstaller) prependToPath(dir string) error {
+	path := dir + ":" + os.Getenv("PATH")
+	if err := os.Setenv("PATH", path); err != nil {
+		return fmt.Errorf("set env: %s", err)
+	}
+	if err := tools.ExportEnvironmentWithEnvman("PATH", path); err != nil {
+		return fmt.Errorf("export env with envman: %s", err)
+	}
+	f.Debugf("Added %s to PATH", dir)
+	return nil
+}
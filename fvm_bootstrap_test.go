@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func Test_fvmReleaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		goarch  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "macOS Apple Silicon",
+			goos:   "darwin",
+			goarch: "arm64",
+			want:   "https://github.com/leoafarias/fvm/releases/download/3.2.1/fvm-3.2.1-macos-arm64.tar.gz",
+		},
+		{
+			name:   "Linux x64",
+			goos:   "linux",
+			goarch: "amd64",
+			want:   "https://github.com/leoafarias/fvm/releases/download/3.2.1/fvm-3.2.1-linux-x64.tar.gz",
+		},
+		{
+			name:    "Windows",
+			goos:    "windows",
+			goarch:  "amd64",
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported architecture",
+			goos:    "linux",
+			goarch:  "386",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fvmReleaseURL("3.2.1", tt.goos, tt.goarch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fvmReleaseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("fvmReleaseURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// fvmFeature is an FVM command or flag which is not supported by every FVM version.
+type fvmFeature string
+
+const (
+	// fvmFeatureSetup is the `fvm install --setup` flag, FVM 3 only sets up the SDK during install if it is given.
+	fvmFeatureSetup fvmFeature = "install --setup"
+	// fvmFeatureAPIList is the `fvm api list` command with JSON output.
+	fvmFeatureAPIList fvmFeature = "api list"
+	// fvmFeatureSkipSizeCalculation is the `fvm api list --skip-size-calculation` flag.
+	fvmFeatureSkipSizeCalculation fvmFeature = "api list --skip-size-calculation"
+	// fvmFeatureUseSkipPubGet is the `fvm use --skip-pub-get` flag, otherwise `fvm use` runs `flutter pub get` in the project.
+	fvmFeatureUseSkipPubGet fvmFeature = "use --skip-pub-get"
+	// fvmFeatureSkipInput is the global `--fvm-skip-input` flag, FVM sometimes does not detect the CI environment and prompts for input.
+	fvmFeatureSkipInput fvmFeature = "--fvm-skip-input"
+)
+
+// fvmFeatureSpec describes the FVM versions supporting a feature, and how to detect it if the version is unknown.
+type fvmFeatureSpec struct {
+	feature fvmFeature
+	// constraint is the range of FVM versions supporting the feature.
+	constraint *semver.Constraints
+	// helpArgs is the command whose `--help` output documents the feature.
+	helpArgs []string
+	// helpPattern matches the help output if the feature is supported.
+	helpPattern *regexp.Regexp
+}
+
+var fvmFeatureTable = []fvmFeatureSpec{
+	{
+		feature:    fvmFeatureSetup,
+		constraint: mustParseConstraint(">=3.0.0"),
+		helpArgs:   []string{"install"},
+		// FVM 2 has a `--skip-setup` flag instead.
+		helpPattern: regexp.MustCompile(`(?m)^\s*(?:-\w, )?--setup\b`),
+	},
+	{
+		feature:    fvmFeatureAPIList,
+		constraint: mustParseConstraint(">=3.1.0"),
+		helpArgs:   []string{"api"},
+		// FVM 2 lists its commands (including `list`) under "Available commands:" if the api command is not found.
+		helpPattern: regexp.MustCompile(`(?s)Available subcommands:.*\n\s+list\s`),
+	},
+	{
+		feature:     fvmFeatureSkipSizeCalculation,
+		constraint:  mustParseConstraint(">=3.1.0"),
+		helpArgs:    []string{"api", "list"},
+		helpPattern: regexp.MustCompile(`--skip-size-calculation\b`),
+	},
+	{
+		feature:     fvmFeatureUseSkipPubGet,
+		constraint:  mustParseConstraint(">=3.0.0"),
+		helpArgs:    []string{"use"},
+		helpPattern: regexp.MustCompile(`--skip-pub-get\b`),
+	},
+	{
+		feature: fvmFeatureSkipInput,
+		// The flag was introduced earlier, but it is only reliable since 3.2.1.
+		constraint:  mustParseConstraint(">=3.2.1"),
+		helpArgs:    nil,
+		helpPattern: regexp.MustCompile(`--fvm-skip-input\b`),
+	},
+}
+
+var fvmVersionRegexp = regexp.MustCompile(`v?\d+\.\d+\.\d+`)
+
+// fvmFeatures is the set of features supported by the available FVM.
+type fvmFeatures map[fvmFeature]bool
+
+func (f fvmFeatures) has(feature fvmFeature) bool {
+	return f[feature]
+}
+
+// parseFVMVersion parses the version from the `fvm --version` output.
+func parseFVMVersion(versionOut string) (*semver.Version, error) {
+	match := fvmVersionRegexp.FindString(versionOut)
+	if match == "" {
+		return nil, fmt.Errorf("parse fvm version: %s: no version found", versionOut)
+	}
+	version, err := semver.NewVersion(match)
+	if err != nil {
+		return nil, fmt.Errorf("parse fvm version: %s: %w", versionOut, err)
+	}
+	return version, nil
+}
+
+// fvmFeaturesForVersion returns the features supported by the FVM version according to the feature table.
+func fvmFeaturesForVersion(version *semver.Version) fvmFeatures {
+	features := fvmFeatures{}
+	for _, spec := range fvmFeatureTable {
+		features[spec.feature] = spec.constraint.Check(version)
+	}
+	return features
+}
+
+// fvmFeaturesFromHelp detects the supported features from the `--help` output of the FVM commands.
+//
+// The output of failed help commands is ignored: unknown commands print the usage of the root command.
+func fvmFeaturesFromHelp(help func(args []string) (string, error)) fvmFeatures {
+	features := fvmFeatures{}
+	outputs := map[string]string{}
+	for _, spec := range fvmFeatureTable {
+		key := strings.Join(spec.helpArgs, " ")
+		out, ok := outputs[key]
+		if !ok {
+			var err error
+			if out, err = help(spec.helpArgs); err != nil {
+				out = ""
+			}
+			outputs[key] = out
+		}
+		features[spec.feature] = spec.helpPattern.MatchString(out)
+	}
+	return features
+}
+
+// fvmDetectFeatures returns the features supported by the available FVM.
+//
+// The features are looked up by the FVM version, if it is unparseable, the help of the FVM commands is probed.
+func (f *FlutterInstaller) fvmDetectFeatures(versionOut string) fvmFeatures {
+	version, err := parseFVMVersion(versionOut)
+	if err == nil {
+		return fvmFeaturesForVersion(version)
+	}
+
+	f.Warnf("Failed to investigate FVM version: %s, probing FVM commands", err)
+	return fvmFeaturesFromHelp(func(args []string) (string, error) {
+		cmd := f.CmdFactory.Create("fvm", append(slices.Clone(args), "--help"), nil)
+		f.Debugf("$ %s", cmd.PrintableCommandArgs())
+		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+		if err != nil {
+			f.Debugf("fvm help: %s %s", err, out)
+		}
+		return out, err
+	})
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var allFVMFeatures = []fvmFeature{fvmFeatureSetup, fvmFeatureAPIList, fvmFeatureSkipSizeCalculation, fvmFeatureUseSkipPubGet, fvmFeatureSkipInput}
+
+func assertFVMFeatures(t *testing.T, got fvmFeatures, want []fvmFeature) {
+	t.Helper()
+	for _, feature := range allFVMFeatures {
+		wantFeature := slices.Contains(want, feature)
+		if got.has(feature) != wantFeature {
+			t.Errorf("feature %s = %v, want %v", feature, got.has(feature), wantFeature)
+		}
+	}
+}
+
+func Test_fvmFeaturesForVersion(t *testing.T) {
+	v3_0 := []fvmFeature{fvmFeatureSetup, fvmFeatureUseSkipPubGet}
+	v3_1 := append(v3_0, fvmFeatureAPIList, fvmFeatureSkipSizeCalculation)
+	v3_2_1 := append(v3_1, fvmFeatureSkipInput)
+
+	tests := []struct {
+		name       string
+		versionOut string
+		want       []fvmFeature
+	}{
+		{name: "1.3", versionOut: "1.3.8"},
+		{name: "2.0", versionOut: "2.0.6"},
+		{name: "2.1", versionOut: "2.1.1"},
+		{name: "2.2", versionOut: "2.2.6"},
+		{name: "2.3", versionOut: "2.3.1"},
+		{name: "2.4", versionOut: "2.4.1"},
+		{name: "3.0", versionOut: "3.0.0", want: v3_0},
+		{name: "3.0 latest", versionOut: "3.0.19", want: v3_0},
+		{name: "3.1", versionOut: "3.1.0", want: v3_1},
+		{name: "3.1 latest", versionOut: "3.1.7", want: v3_1},
+		{name: "3.2 before skip input flag worked", versionOut: "3.2.0", want: v3_1},
+		{name: "3.2 skip input flag working", versionOut: "3.2.1", want: v3_2_1},
+		{name: "v prefix", versionOut: "v3.3.3", want: v3_2_1},
+		{name: "Long version", versionOut: "13.172.76", want: v3_2_1},
+		{name: "fvm and flutter version", versionOut: "fvm 3.1.6 with flutter 2.1.3", want: v3_1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := parseFVMVersion(tt.versionOut)
+			if err != nil {
+				t.Fatalf("parseFVMVersion() error = %v", err)
+			}
+			assertFVMFeatures(t, fvmFeaturesForVersion(version), tt.want)
+		})
+	}
+}
+
+func Test_parseFVMVersion_invalid(t *testing.T) {
+	for _, versionOut := range []string{"fvm version 3.2", "fvm version 3.b.6", ""} {
+		if _, err := parseFVMVersion(versionOut); err == nil {
+			t.Errorf("parseFVMVersion(%s) expected error", versionOut)
+		}
+	}
+}
+
+// fvm2UnknownCommandHelp is the output of FVM 2 for unknown commands, e.g. `fvm api --help` (exit code 64).
+const fvm2UnknownCommandHelp = `Could not find a command named "api".
+
+Usage: fvm <command> [arguments]
+
+Global options:
+-h, --help       Print this usage information.
+    --verbose    Print verbose output.
+    --version    current version
+
+Available commands:
+  config    Config fvm options
+  dart      Proxies Dart Commands
+  doctor    Shows information about environment, and project configuration.
+  exec      Executes scripts with the configured Flutter SDK
+  flavor    Switches between different project flavors
+  flutter   Proxies Flutter Commands
+  global    Sets Flutter SDK Version as a global
+  install   Installs Flutter SDK Version
+  list      Lists installed Flutter SDK Versions
+  releases  View all Flutter SDK releases available for install.
+  remove    Removes Flutter SDK Version
+  spawn     Spawns a command on a Flutter version
+  use       Sets Flutter SDK Version you would like to use in a project
+
+Run "fvm help <command>" for more information about a command.`
+
+func Test_fvmFeaturesFromHelp(t *testing.T) {
+	const rootHelp = `Flutter Version Management: A cli to manage Flutter SDK versions.
+
+Usage: fvm <command> [arguments]
+
+Global options:
+-h, --help              Print this usage information.
+    --fvm-skip-input    Skip user input prompts`
+
+	type helpResult struct {
+		out string
+		err error
+	}
+	exitStatus64 := errors.New("exit status 64")
+
+	tests := []struct {
+		name string
+		help map[string]helpResult
+		want []fvmFeature
+	}{
+		{
+			name: "FVM 2",
+			help: map[string]helpResult{
+				"":         {out: strings.Replace(fvm2UnknownCommandHelp, "Could not find a command named \"api\".\n\n", "", 1)},
+				"install":  {out: "Installs Flutter SDK Version\n\nUsage: fvm install <version>\n-h, --help          Print this usage information.\n    --skip-setup    Skips Flutter setup after install"},
+				"use":      {out: "Which Flutter SDK Version you would like to use\n\nUsage: fvm use {version}\n-h, --help     Print this usage information.\n-f, --force    Skips command guards that does Flutter project checks."},
+				"api":      {out: fvm2UnknownCommandHelp, err: exitStatus64},
+				"api list": {out: strings.Replace(fvm2UnknownCommandHelp, "\"api\"", "\"api list\"", 1), err: exitStatus64},
+			},
+		},
+		{
+			name: "FVM 3.2",
+			help: map[string]helpResult{
+				"":         {out: rootHelp},
+				"install":  {out: "Installs a Flutter SDK version\n\nUsage: fvm install [version]\n-s, --setup    Builds SDK after install after install"},
+				"api":      {out: "JSON API for FVM data\n\nUsage: fvm api <subcommand> [arguments]\n-h, --help    Print this usage information.\n\nAvailable subcommands:\n  context    Gets context information\n  list       Lists installed Flutter SDK Versions\n  project    Gets project information"},
+				"api list": {out: "Lists installed Flutter SDK Versions\n\n-s, --skip-size-calculation    Skip calculating the size of the versions"},
+				"use":      {out: "Sets Flutter SDK Version you would like to use in a project\n\nUsage: fvm use {version}\n-f, --force           Skips command guards that does Flutter project checks.\n-s, --skip-setup      Skips Flutter setup after install\n    --skip-pub-get    Skip resolving dependencies after switching Flutter SDK"},
+			},
+			want: allFVMFeatures,
+		},
+		{
+			name: "Failed help command",
+			help: map[string]helpResult{
+				"install": {out: "-s, --setup    Builds SDK after install after install", err: exitStatus64},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := map[string]int{}
+			got := fvmFeaturesFromHelp(func(args []string) (string, error) {
+				key := strings.Join(args, " ")
+				calls[key]++
+				return tt.help[key].out, tt.help[key].err
+			})
+			assertFVMFeatures(t, got, tt.want)
+			for key, count := range calls {
+				if count > 1 {
+					t.Errorf("help of %q probed %d times", key, count)
+				}
+			}
+		})
+	}
+}
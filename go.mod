@@ -3,18 +3,18 @@ module github.com/bitrise-steplib/bitrise-step-flutter-installer
 go 1.21
 
 require (
+	github.com/Masterminds/semver/v3 v3.3.1
 	github.com/bitrise-io/go-flutter v0.1.1
 	github.com/bitrise-io/go-steputils v1.0.6
 	github.com/bitrise-io/go-steputils/v2 v2.0.0-alpha.37
 	github.com/bitrise-io/go-utils v1.0.15
 	github.com/bitrise-io/go-utils/v2 v2.0.0-alpha.25
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/Masterminds/semver/v3 v3.3.1 // indirect
 	github.com/gofrs/uuid/v5 v5.3.2 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/hashicorp/go-retryablehttp v0.7.7 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
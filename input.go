@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+
+	logv2 "github.com/bitrise-io/go-utils/v2/log"
+)
+
+// migrateLegacyInput moves values which were historically overloaded into the version input
+// to their dedicated inputs.
+//
+// Previously both the bundle URL and the version@channel notation were given in the version input,
+// these configurations keep working, but a warning is printed to move them to the new inputs.
+func migrateLegacyInput(logger logv2.Logger, input Input) (Input, error) {
+	version := strings.TrimSpace(input.Version)
+
+	if isURL(version) {
+		if input.BundleURL != "" && input.BundleURL != version {
+			return input, fmt.Errorf("both 'version' (%s) and 'bundle_url' (%s) inputs specify a bundle URL, please use the 'bundle_url' input only", version, input.BundleURL)
+		}
+		logger.Warnf("Input: 'version' contains a bundle URL, please use the 'bundle_url' input instead.")
+		input.BundleURL = version
+		input.Version = ""
+		return input, nil
+	}
+
+	// Fallback lists are not migrated, the version of each entry can have its own channel (e.g. `3.24.5@stable`).
+	if strings.Contains(version, "\n") {
+		return input, nil
+	}
+
+	if versionPart, channelPart, found := strings.Cut(version, "@"); found {
+		versionPart, channelPart = strings.TrimSpace(versionPart), strings.TrimSpace(channelPart)
+		if input.Channel != "" && input.Channel != channelPart {
+			return input, fmt.Errorf("'version' input (%s) specifies channel '%s', but 'channel' input is '%s'", version, channelPart, input.Channel)
+		}
+		logger.Warnf("Input: 'version' contains a channel (%s), please use the 'channel' input instead.", version)
+		input.Version = versionPart
+		input.Channel = channelPart
+	}
+
+	return input, nil
+}
+
+// validateInput checks the version related inputs and returns a descriptive error for invalid configurations.
+func validateInput(input Input) error {
+	if input.Channel != "" && !slices.Contains(Channels, input.Channel) {
+		return fmt.Errorf("invalid 'channel' input: %s, available channels: %s", input.Channel, strings.Join(Channels, ", "))
+	}
+
+	if input.FVMScope != "" && !slices.Contains(FVMScopes, input.FVMScope) {
+		return fmt.Errorf("invalid 'fvm_scope' input: %s, available scopes: %s", input.FVMScope, strings.Join(FVMScopes, ", "))
+	}
+
+	if input.AndroidCompatibilityCheck != "" && !slices.Contains(AndroidCompatibilityChecks, input.AndroidCompatibilityCheck) {
+		return fmt.Errorf("invalid 'android_compatibility_check' input: %s, available values: %s", input.AndroidCompatibilityCheck, strings.Join(AndroidCompatibilityChecks, ", "))
+	}
+
+	if input.LinuxDesktopPrerequisites != "" && !slices.Contains(LinuxDesktopPrerequisitesModes, input.LinuxDesktopPrerequisites) {
+		return fmt.Errorf("invalid 'linux_desktop_prerequisites' input: %s, available values: %s", input.LinuxDesktopPrerequisites, strings.Join(LinuxDesktopPrerequisitesModes, ", "))
+	}
+
+	if input.PubGet != "" && !slices.Contains(PubGetModes, input.PubGet) {
+		return fmt.Errorf("invalid 'pub_get' input: %s, available values: %s", input.PubGet, strings.Join(PubGetModes, ", "))
+	}
+
+	if _, err := NewVersionSpecifiers(input.Version); err != nil {
+		return fmt.Errorf("invalid 'version' input: %s", err)
+	}
+
+	if _, err := parsePubTokens(input.PubTokens); err != nil {
+		return fmt.Errorf("invalid 'pub_tokens' input: %s", err)
+	}
+
+	if _, err := parseDartGlobalTools(input.DartGlobalTools); err != nil {
+		return fmt.Errorf("invalid 'dart_global_tools' input: %s", err)
+	}
+
+	if _, err := parseVersionSourcePriority(input.VersionSourcePriority); err != nil {
+		return fmt.Errorf("invalid 'version_source_priority' input: %s", err)
+	}
+
+	if _, err := parsePrecachePlatforms(input.PrecachePlatforms); err != nil {
+		return fmt.Errorf("invalid 'precache_platforms' input: %s", err)
+	}
+
+	if _, err := parseFlutterConfigSettings(input.FlutterConfig); err != nil {
+		return fmt.Errorf("invalid 'flutter_config' input: %s", err)
+	}
+
+	if input.BundleURL == "" {
+		return nil
+	}
+
+	if err := validateFlutterURL(input.BundleURL); err != nil {
+		return fmt.Errorf("invalid 'bundle_url' input: %s", err)
+	}
+	if err := validateBundlePlatform(input.BundleURL, runtime.GOOS, runtime.GOARCH); err != nil {
+		return fmt.Errorf("invalid 'bundle_url' input: %s", err)
+	}
+
+	return nil
+}
+
+// validateBundlePlatform checks if the bundle URL is built for the given OS and architecture.
+//
+// Expecting URL similar to: https://storage.googleapis.com/flutter_infra_release/releases/stable/macos/flutter_macos_arm64_3.32.5-stable.zip
+func validateBundlePlatform(bundleURL, goos, goarch string) error {
+	flutterURL, err := url.Parse(bundleURL)
+	if err != nil {
+		return err
+	}
+
+	platforms := map[string]string{
+		"darwin":  "macos",
+		"linux":   "linux",
+		"windows": "windows",
+	}
+	expectedPlatform, ok := platforms[goos]
+	if !ok {
+		return fmt.Errorf("unsupported operating system: %s", goos)
+	}
+
+	pathParts := strings.Split(strings.Trim(flutterURL.Path, "/"), "/")
+	bundlePlatform := ""
+	for _, part := range pathParts[:len(pathParts)-1] {
+		for _, platform := range platforms {
+			if part == platform {
+				bundlePlatform = platform
+			}
+		}
+	}
+	if bundlePlatform == "" {
+		return fmt.Errorf("could not determine the platform of the bundle, expecting one of: macos, linux, windows in the path")
+	}
+	if bundlePlatform != expectedPlatform {
+		return fmt.Errorf("bundle is built for %s, but the Step runs on %s, use a bundle from the %s releases instead", bundlePlatform, expectedPlatform, expectedPlatform)
+	}
+
+	fileName := path.Base(flutterURL.Path)
+	if strings.Contains(fileName, "_arm64_") && goarch != "arm64" {
+		return fmt.Errorf("bundle is built for arm64, but the Step runs on %s, use the bundle without the arm64 suffix instead", goarch)
+	}
+
+	return nil
+}
+
+// splitListInput splits a comma or newline separated list input, dropping the empty entries.
+func splitListInput(input string) []string {
+	var entries []string
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if entry := strings.TrimSpace(field); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func isURL(input string) bool {
+	return strings.HasPrefix(input, "https://") || strings.HasPrefix(input, "http://")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	logv2 "github.com/bitrise-io/go-utils/v2/log"
+)
+
+func Test_migrateLegacyInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   Input
+		want    Input
+		wantErr bool
+	}{
+		{
+			name:  "Version only",
+			input: Input{Version: "3.32.5"},
+			want:  Input{Version: "3.32.5"},
+		},
+		{
+			name:  "Bundle URL in version",
+			input: Input{Version: bundleURL},
+			want:  Input{BundleURL: bundleURL},
+		},
+		{
+			name:  "Same bundle URL in both inputs",
+			input: Input{Version: bundleURL, BundleURL: bundleURL},
+			want:  Input{BundleURL: bundleURL},
+		},
+		{
+			name:    "Different bundle URLs",
+			input:   Input{Version: bundleURL, BundleURL: "https://storage.googleapis.com/flutter_infra_release/releases/stable/linux/flutter_linux_3.32.5-stable.tar.xz"},
+			wantErr: true,
+		},
+		{
+			name:  "Version with channel",
+			input: Input{Version: "3.33.0-0.2.pre@beta"},
+			want:  Input{Version: "3.33.0-0.2.pre", Channel: "beta"},
+		},
+		{
+			name:  "Version with channel and matching channel input",
+			input: Input{Version: "3.33.0-0.2.pre@beta", Channel: "beta"},
+			want:  Input{Version: "3.33.0-0.2.pre", Channel: "beta"},
+		},
+		{
+			name:    "Version with channel and conflicting channel input",
+			input:   Input{Version: "3.33.0-0.2.pre@beta", Channel: "stable"},
+			wantErr: true,
+		},
+		{
+			name:  "Fallback list with channels",
+			input: Input{Version: "3.24.5@stable\n3.22.0"},
+			want:  Input{Version: "3.24.5@stable\n3.22.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := migrateLegacyInput(logv2.NewLogger(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("migrateLegacyInput() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("migrateLegacyInput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateInput_version(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		wantErr string
+	}{
+		{
+			name:    "Version list",
+			version: "3.24.5\n>=3.22.0 <3.25.0\nstable",
+		},
+		{
+			name:    "Invalid line",
+			version: "3.24.5\n\nfoobar",
+			wantErr: "line 3 (foobar)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInput(Input{Version: tt.version})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateInput() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateInput() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateBundlePlatform(t *testing.T) {
+	tests := []struct {
+		name      string
+		bundleURL string
+		goos      string
+		goarch    string
+		wantErr   bool
+	}{
+		{
+			name:      "macOS bundle on macOS",
+			bundleURL: "https://storage.googleapis.com/flutter_infra_release/releases/stable/macos/flutter_macos_3.32.5-stable.zip",
+			goos:      "darwin",
+			goarch:    "amd64",
+		},
+		{
+			name:      "macOS arm64 bundle on Apple Silicon",
+			bundleURL: "https://storage.googleapis.com/flutter_infra_release/releases/stable/macos/flutter_macos_arm64_3.32.5-stable.zip",
+			goos:      "darwin",
+			goarch:    "arm64",
+		},
+		{
+			name:      "macOS arm64 bundle on Intel",
+			bundleURL: "https://storage.googleapis.com/flutter_infra_release/releases/stable/macos/flutter_macos_arm64_3.32.5-stable.zip",
+			goos:      "darwin",
+			goarch:    "amd64",
+			wantErr:   true,
+		},
+		{
+			name:      "Linux bundle on Linux",
+			bundleURL: "https://storage.googleapis.com/flutter_infra_release/releases/stable/linux/flutter_linux_3.32.5-stable.tar.xz",
+			goos:      "linux",
+			goarch:    "amd64",
+		},
+		{
+			name:      "macOS bundle on Linux",
+			bundleURL: "https://storage.googleapis.com/flutter_infra/releases/beta/macos/flutter_macos_v1.6.3-beta.zip",
+			goos:      "linux",
+			goarch:    "amd64",
+			wantErr:   true,
+		},
+		{
+			name:      "Unknown platform",
+			bundleURL: "https://storage.googleapis.com/flutter_infra_release/releases/stable/flutter_3.32.5-stable.zip",
+			goos:      "linux",
+			goarch:    "amd64",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBundlePlatform(tt.bundleURL, tt.goos, tt.goarch); (err != nil) != tt.wantErr {
+				t.Errorf("validateBundlePlatform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const (
+	LinuxDesktopPrerequisitesAuto    = "auto"
+	LinuxDesktopPrerequisitesCheck   = "check"
+	LinuxDesktopPrerequisitesInstall = "install"
+	LinuxDesktopPrerequisitesOff     = "off"
+)
+
+var LinuxDesktopPrerequisitesModes = []string{LinuxDesktopPrerequisitesAuto, LinuxDesktopPrerequisitesCheck, LinuxDesktopPrerequisitesInstall, LinuxDesktopPrerequisitesOff}
+
+// linuxPrerequisite is a tool (found in PATH) or library (found with pkg-config) required to build Linux desktop apps.
+type linuxPrerequisite struct {
+	name string
+	// pkgConfigModule is set for libraries, checked with `pkg-config --exists`.
+	pkgConfigModule string
+	aptPackage      string
+}
+
+// linuxDesktopPrerequisites are the build dependencies of Flutter Linux desktop apps.
+var linuxDesktopPrerequisites = []linuxPrerequisite{
+	{name: "clang", aptPackage: "clang"},
+	{name: "cmake", aptPackage: "cmake"},
+	{name: "ninja", aptPackage: "ninja-build"},
+	{name: "pkg-config", aptPackage: "pkg-config"},
+	{name: "GTK 3 headers", pkgConfigModule: "gtk+-3.0", aptPackage: "libgtk-3-dev"},
+}
+
+// findMissingLinuxPrerequisites returns the prerequisites not found in PATH or with pkg-config.
+func findMissingLinuxPrerequisites(hasTool func(name string) bool, hasPkgConfigModule func(module string) bool) []linuxPrerequisite {
+	var missing []linuxPrerequisite
+	for _, prerequisite := range linuxDesktopPrerequisites {
+		found := false
+		if prerequisite.pkgConfigModule != "" {
+			found = hasTool("pkg-config") && hasPkgConfigModule(prerequisite.pkgConfigModule)
+		} else {
+			found = hasTool(prerequisite.name)
+		}
+		if !found {
+			missing = append(missing, prerequisite)
+		}
+	}
+	return missing
+}
+
+func aptPackages(prerequisites []linuxPrerequisite) []string {
+	var packages []string
+	for _, prerequisite := range prerequisites {
+		packages = append(packages, prerequisite.aptPackage)
+	}
+	return packages
+}
+
+func (f *FlutterInstaller) missingLinuxPrerequisites() []linuxPrerequisite {
+	hasTool := func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	}
+	hasPkgConfigModule := func(module string) bool {
+		cmd := f.CmdFactory.Create("pkg-config", []string{"--exists", module}, nil)
+		return cmd.Run() == nil
+	}
+	return findMissingLinuxPrerequisites(hasTool, hasPkgConfigModule)
+}
+
+// shouldCheckLinuxPrerequisites checks if the Linux desktop prerequisites should be checked:
+// on Linux, if enabled by the linux_desktop_prerequisites input or the project has a linux directory.
+func (f *FlutterInstaller) shouldCheckLinuxPrerequisites() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+
+	switch f.Input.LinuxDesktopPrerequisites {
+	case LinuxDesktopPrerequisitesOff:
+		return false
+	case LinuxDesktopPrerequisitesCheck, LinuxDesktopPrerequisitesInstall:
+		return true
+	default:
+		info, err := os.Stat(filepath.Join(f.Input.ProjectLocation, "linux"))
+		return err == nil && info.IsDir()
+	}
+}
+
+// checkLinuxPrerequisites reports the missing build dependencies of Linux desktop apps,
+// and installs them with apt-get if the linux_desktop_prerequisites input is `install`.
+func (f *FlutterInstaller) checkLinuxPrerequisites() error {
+	if !f.shouldCheckLinuxPrerequisites() {
+		return nil
+	}
+
+	f.Infof("Check Linux desktop prerequisites")
+
+	missing := f.missingLinuxPrerequisites()
+	if len(missing) == 0 {
+		f.Donef("Linux desktop prerequisites are installed")
+		return nil
+	}
+
+	var names []string
+	for _, prerequisite := range missing {
+		names = append(names, prerequisite.name)
+	}
+	packages := aptPackages(missing)
+
+	if f.Input.LinuxDesktopPrerequisites != LinuxDesktopPrerequisitesInstall {
+		f.Warnf("Missing Linux desktop prerequisites: %s", strings.Join(names, ", "))
+		f.Printf("Install them with: sudo apt-get install -y %s", strings.Join(packages, " "))
+		return nil
+	}
+
+	f.Printf("Installing missing Linux desktop prerequisites: %s", strings.Join(names, ", "))
+	if err := f.aptGetInstall(packages); err != nil {
+		return err
+	}
+
+	if missing := f.missingLinuxPrerequisites(); len(missing) > 0 {
+		return fmt.Errorf("prerequisites are still missing after installing them: %s", strings.Join(aptPackages(missing), ", "))
+	}
+	f.Donef("Linux desktop prerequisites installed")
+
+	return nil
+}
+
+// aptGetInstall installs the packages with apt-get, using sudo if not running as root.
+func (f *FlutterInstaller) aptGetInstall(packages []string) error {
+	cmdOpts := command.Opts{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Env:    []string{"DEBIAN_FRONTEND=noninteractive"},
+	}
+
+	for _, args := range [][]string{
+		{"apt-get", "update"},
+		append([]string{"apt-get", "install", "-y", "--no-install-recommends"}, packages...),
+	} {
+		name := args[0]
+		if os.Geteuid() != 0 {
+			name, args = "sudo", append([]string{"-E"}, args...)
+		} else {
+			args = args[1:]
+		}
+
+		cmd := f.CmdFactory.Create(name, args, &cmdOpts)
+		f.Donef("$ %s", cmd.PrintableCommandArgs())
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %s", cmd.PrintableCommandArgs(), err)
+		}
+	}
+
+	return nil
+}
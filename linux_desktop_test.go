@@ -0,0 +1,44 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_findMissingLinuxPrerequisites(t *testing.T) {
+	tests := []struct {
+		name    string
+		tools   []string
+		modules []string
+		want    []string
+	}{
+		{
+			name:    "Everything installed",
+			tools:   []string{"clang", "cmake", "ninja", "pkg-config"},
+			modules: []string{"gtk+-3.0"},
+		},
+		{
+			name:    "Missing tools and GTK",
+			tools:   []string{"clang", "pkg-config"},
+			modules: []string{"glib-2.0"},
+			want:    []string{"cmake", "ninja-build", "libgtk-3-dev"},
+		},
+		{
+			name:    "GTK is not found without pkg-config",
+			tools:   []string{"clang", "cmake", "ninja"},
+			modules: []string{"gtk+-3.0"},
+			want:    []string{"pkg-config", "libgtk-3-dev"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasTool := func(name string) bool { return slices.Contains(tt.tools, name) }
+			hasModule := func(module string) bool { return slices.Contains(tt.modules, module) }
+
+			got := aptPackages(findMissingLinuxPrerequisites(hasTool, hasModule))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findMissingLinuxPrerequisites() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
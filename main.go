@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -13,8 +14,30 @@ import (
 )
 
 type Input struct {
-	Version string `env:"version"`
-	IsDebug bool   `env:"is_debug"`
+	Version                   string `env:"version"`
+	Channel                   string `env:"channel"`
+	BundleURL                 string `env:"bundle_url"`
+	StrictVersionConsistency  bool   `env:"strict_version_consistency"`
+	ProjectLocation           string `env:"project_location"`
+	VersionSourcePriority     string `env:"version_source_priority"`
+	FVMScope                  string `env:"fvm_scope"`
+	FVMFlavor                 string `env:"fvm_flavor"`
+	InstallFVM                bool   `env:"install_fvm"`
+	InstallASDFPlugin         bool   `env:"install_asdf_plugin"`
+	ASDFPluginURL             string `env:"asdf_plugin_url"`
+	ASDFPluginRef             string `env:"asdf_plugin_ref"`
+	UseVersionLock            bool   `env:"use_version_lock"`
+	PrecachePlatforms         string `env:"precache_platforms"`
+	FlutterConfig             string `env:"flutter_config"`
+	DoctorFailOnValidators    string `env:"doctor_fail_on_validators"`
+	AcceptAndroidLicenses     bool   `env:"accept_android_licenses"`
+	AndroidCompatibilityCheck string `env:"android_compatibility_check"`
+	LinuxDesktopPrerequisites string `env:"linux_desktop_prerequisites"`
+	PubGet                    string `env:"pub_get"`
+	PubTokens                 string `env:"pub_tokens"`
+	DartGlobalTools           string `env:"dart_global_tools"`
+	SmokeTest                 bool   `env:"smoke_test"`
+	IsDebug                   bool   `env:"is_debug"`
 }
 
 type FlutterInstaller struct {
@@ -22,6 +45,11 @@ type FlutterInstaller struct {
 	EnvRepo    env.Repository
 	CmdFactory command.Factory
 	Input      Input
+
+	// releases loads the official Flutter releases once per run, see releaseCatalogue.
+	releases *releaseCatalogueLoader
+	// asdfPlugin is the result of setting up the asdf flutter plugin, see ensureASDFPlugin.
+	asdfPlugin *asdfPluginSetup
 }
 
 func main() {
@@ -36,6 +64,11 @@ func run() exitcode.ExitCode {
 	}
 
 	if err := f.Run(); err != nil {
+		var smokeErr smokeTestError
+		if errors.As(err, &smokeErr) {
+			f.Errorf(errorutil.FormattedError(fmt.Errorf("Flutter was installed, but the SDK is not usable: %w", smokeErr)))
+			return exitcode.Failure
+		}
 		f.Errorf(errorutil.FormattedError(fmt.Errorf("execute Step: %w", err)))
 		return exitcode.Failure
 	}
@@ -49,7 +82,47 @@ func (f *FlutterInstaller) Run() error {
 		return fmt.Errorf("ensure Flutter version: %w", err)
 	}
 
-	if f.Input.IsDebug {
+	if err := f.addPubTokens(); err != nil {
+		return fmt.Errorf("configure pub tokens: %w", err)
+	}
+
+	if err := f.activateDartGlobalTools(); err != nil {
+		return fmt.Errorf("activate global Dart tools: %w", err)
+	}
+
+	if err := f.applyFlutterConfig(); err != nil {
+		return fmt.Errorf("apply Flutter config: %w", err)
+	}
+
+	if f.Input.AcceptAndroidLicenses {
+		if err := f.acceptAndroidLicenses(); err != nil {
+			return fmt.Errorf("accept Android licenses: %w", err)
+		}
+	}
+
+	if err := f.checkAndroidToolchain(); err != nil {
+		return fmt.Errorf("check Android toolchain: %w", err)
+	}
+
+	if err := f.checkLinuxPrerequisites(); err != nil {
+		return fmt.Errorf("check Linux desktop prerequisites: %w", err)
+	}
+
+	if err := f.precache(); err != nil {
+		return fmt.Errorf("precache Flutter artifacts: %w", err)
+	}
+
+	if err := f.pubGet(); err != nil {
+		return fmt.Errorf("resolve project dependencies: %w", err)
+	}
+
+	if f.Input.SmokeTest {
+		if err := f.runSmokeTest(); err != nil {
+			return err
+		}
+	}
+
+	if f.Input.IsDebug || f.Input.DoctorFailOnValidators != "" {
 		if err := f.runFlutterDoctor(); err != nil {
 			return err
 		}
@@ -79,9 +152,31 @@ func ConfigureFlutterInstaller() (*FlutterInstaller, error) {
 	logger := logv2.NewLogger()
 	logger.EnableDebugLog(input.IsDebug)
 
-	if input.Version == "" {
-		logger.Warnf("Input: 'Flutter SDK git repository version' (version) is not specified, using 'stable' as a default version.")
-		input.Version = "stable"
+	input, err := migrateLegacyInput(logger, input)
+	if err != nil {
+		return &FlutterInstaller{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return &FlutterInstaller{}, err
+	}
+
+	if input.ProjectLocation == "" {
+		input.ProjectLocation = "."
+	}
+	if input.FVMScope == "" {
+		input.FVMScope = FVMScopeGlobal
+	}
+	if input.AndroidCompatibilityCheck == "" {
+		input.AndroidCompatibilityCheck = AndroidCompatibilityCheckWarn
+	}
+	if input.LinuxDesktopPrerequisites == "" {
+		input.LinuxDesktopPrerequisites = LinuxDesktopPrerequisitesAuto
+	}
+	if input.PubGet == "" {
+		input.PubGet = PubGetOff
+	}
+	if input.ASDFPluginURL == "" {
+		input.ASDFPluginURL = ASDFPluginDefaultURL
 	}
 
 	if err := envRepo.Set("CI", "true"); err != nil {
@@ -94,18 +189,3 @@ func ConfigureFlutterInstaller() (*FlutterInstaller, error) {
 
 	return &fi, nil
 }
-
-func (f *FlutterInstaller) runFlutterDoctor() error {
-	f.Infof("Check flutter doctor")
-
-	cmdOpts := command.Opts{
-		Stdout: os.Stdout,
-		Stderr: os.Stderr,
-	}
-	doctorCmd := f.CmdFactory.Create("flutter", []string{"doctor"}, &cmdOpts)
-	f.Donef("$ %s", doctorCmd.PrintableCommandArgs())
-	if err := doctorCmd.Run(); err != nil {
-		return fmt.Errorf("check flutter doctor: %s", err)
-	}
-	return nil
-}
@@ -18,9 +18,9 @@ import (
 // DownloadFlutterSDK downloads the Flutter SDK from the specified version or channel.
 //
 // It checks if the version is specified in the input or required parameters.
-// If input is a valid URL, it downloads and unarchives the Flutter SDK bundle.
+// If the bundle URL input is specified, it downloads and unarchives the Flutter SDK bundle.
 func (f *FlutterInstaller) DownloadFlutterSDK(required flutterVersion) error {
-	if required.version == "" && required.channel == "" && f.Input.Version == "" {
+	if required.version == nil && required.channel == "" && f.Input.BundleURL == "" {
 		return fmt.Errorf("input: 'Flutter SDK git repository version' (version) is not specified")
 	}
 
@@ -38,17 +38,17 @@ func (f *FlutterInstaller) DownloadFlutterSDK(required flutterVersion) error {
 		return fmt.Errorf("create folder (%s): %s", sdkPathParent, err)
 	}
 
-	if validateFlutterURL(f.Input.Version) == nil {
-		f.Infof("Downloading and unarchiving Flutter from installation bundle: %s", required)
+	if f.Input.BundleURL != "" {
+		f.Infof("Downloading and unarchiving Flutter from installation bundle: %s", f.Input.BundleURL)
 
-		if err := f.downloadAndUnarchiveBundle(f.Input.Version, sdkPathParent); err != nil {
+		if err := f.downloadAndUnarchiveBundle(f.Input.BundleURL, sdkPathParent); err != nil {
 			return fmt.Errorf("download and unarchive bundle: %s", err)
 		}
 	} else {
 		f.Infof("Cloning Flutter from the git repository (https://github.com/flutter/flutter.git)")
-		f.Infof("Selected branch/tag: %s", required)
+		f.Infof("Selected branch/tag: %s", f.NewVersionString(required))
 
-		branchOrTag := required.version
+		branchOrTag := required.versionString()
 		if branchOrTag == "" {
 			branchOrTag = required.channel
 		}
@@ -63,7 +63,7 @@ func (f *FlutterInstaller) DownloadFlutterSDK(required flutterVersion) error {
 		}, nil)
 		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
 		if err != nil {
-			return fmt.Errorf("clone git repo for tag/branch: %s: %s", required, out)
+			return fmt.Errorf("clone git repo for tag/branch: %s: %s", f.NewVersionString(required), out)
 		}
 	}
 
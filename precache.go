@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const (
+	precacheAttempts  = 3
+	precacheRetryWait = 10 * time.Second
+)
+
+// PrecachePlatforms are the platforms of the precache_platforms input, each one is a `flutter precache` flag.
+var PrecachePlatforms = []string{"android", "ios", "web", "linux", "macos", "fuchsia", "universal"}
+
+// precacheStampNames maps the platforms to the stamp file (bin/cache/<name>.stamp) of their engine artifacts.
+var precacheStampNames = map[string]string{
+	"android":   "android-sdk",
+	"ios":       "ios-sdk",
+	"web":       "flutter_web_sdk",
+	"linux":     "linux-sdk",
+	"macos":     "macos-sdk",
+	"fuchsia":   "flutter_runner",
+	"universal": "flutter_sdk",
+}
+
+// parsePrecachePlatforms parses the comma or newline separated list of platforms.
+func parsePrecachePlatforms(input string) ([]string, error) {
+	var platforms []string
+	for _, field := range splitListInput(input) {
+		platform := strings.ToLower(field)
+		if !slices.Contains(PrecachePlatforms, platform) {
+			return nil, fmt.Errorf("unknown platform: %s, available platforms: %s", platform, strings.Join(PrecachePlatforms, ", "))
+		}
+		if !slices.Contains(platforms, platform) {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms, nil
+}
+
+// precacheArgs returns the `flutter precache` arguments downloading the artifacts of the platforms.
+func precacheArgs(platforms []string) []string {
+	args := []string{"precache"}
+	for _, platform := range platforms {
+		args = append(args, "--"+platform)
+	}
+	return args
+}
+
+// isPrecached checks if the artifacts of every platform are stamped with the engine revision in the SDK's bin/cache.
+func isPrecached(flutterRoot, engineRevision string, platforms []string) bool {
+	if flutterRoot == "" || engineRevision == "" {
+		return false
+	}
+	for _, platform := range platforms {
+		stamp, err := os.ReadFile(filepath.Join(flutterRoot, "bin", "cache", precacheStampNames[platform]+".stamp"))
+		if err != nil || strings.TrimSpace(string(stamp)) != engineRevision {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFlutterRootAndEngine returns the SDK root and engine revision from the `flutter --version --machine` output.
+func parseFlutterRootAndEngine(versionOut string) (string, string, error) {
+	var data struct {
+		FlutterRoot    string `json:"flutterRoot"`
+		EngineRevision string `json:"engineRevision"`
+	}
+	if err := unmarshalFlutterJSON(versionOut, &data); err != nil {
+		return "", "", fmt.Errorf("parse flutter version output: %w", err)
+	}
+	return data.FlutterRoot, data.EngineRevision, nil
+}
+
+// precache downloads the engine artifacts of the platforms of the precache_platforms input,
+// so that subsequent `flutter build` commands do not download them.
+//
+// Transient failures (e.g. network errors) are retried, artifacts already in the cache are not downloaded again.
+func (f *FlutterInstaller) precache() error {
+	platforms, err := parsePrecachePlatforms(f.Input.PrecachePlatforms)
+	if err != nil {
+		return err
+	}
+	if len(platforms) == 0 {
+		return nil
+	}
+
+	f.Infof("Precache Flutter artifacts: %s", strings.Join(platforms, ", "))
+
+	versionCmd := f.CmdFactory.Create("flutter", []string{"--version", "--machine"}, nil)
+	if out, err := versionCmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		f.Debugf("get flutter version: %s %s", err, out)
+	} else if flutterRoot, engineRevision, err := parseFlutterRootAndEngine(out); err != nil {
+		f.Debugf("%s", err)
+	} else if isPrecached(flutterRoot, engineRevision, platforms) {
+		f.Donef("Artifacts are already cached in %s", filepath.Join(flutterRoot, "bin", "cache"))
+		return nil
+	}
+
+	cmdOpts := command.Opts{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		precacheCmd := f.CmdFactory.Create("flutter", precacheArgs(platforms), &cmdOpts)
+		f.Donef("$ %s", precacheCmd.PrintableCommandArgs())
+		err := precacheCmd.Run()
+		if err == nil {
+			break
+		}
+		if attempt == precacheAttempts {
+			return fmt.Errorf("precache artifacts after %d attempts: %s", attempt, err)
+		}
+		f.Warnf("Precache attempt %d failed, retrying in %s: %s", attempt, precacheRetryWait, err)
+		time.Sleep(precacheRetryWait)
+	}
+	f.Donef("Precached artifacts in %s", time.Since(start).Round(time.Second))
+
+	return nil
+}
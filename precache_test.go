@@ -0,0 +1,83 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func Test_parsePrecachePlatforms(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "Empty",
+			input: "",
+		},
+		{
+			name:  "Comma and newline separated",
+			input: "android, iOS\nweb,android",
+			want:  []string{"android", "ios", "web"},
+		},
+		{
+			name:    "Unknown platform",
+			input:   "android,windows",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrecachePlatforms(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePrecachePlatforms() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parsePrecachePlatforms() = %v, want %v", got, tt.want)
+			}
+			if args := precacheArgs(got); len(args) != len(got)+1 {
+				t.Errorf("precacheArgs() = %v, want a flag per platform", args)
+			}
+		})
+	}
+}
+
+func Test_isPrecached(t *testing.T) {
+	const engineRevision = "a18df97ca57a249df5d8d68cd0820600223ce262"
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "bin", "cache", "android-sdk.stamp"), engineRevision+"\n")
+	writeTestFile(t, filepath.Join(root, "bin", "cache", "ios-sdk.stamp"), "0000000000000000000000000000000000000000")
+
+	tests := []struct {
+		name      string
+		platforms []string
+		want      bool
+	}{
+		{name: "Stamped", platforms: []string{"android"}, want: true},
+		{name: "Stamped with another engine revision", platforms: []string{"android", "ios"}},
+		{name: "Not downloaded", platforms: []string{"android", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPrecached(root, engineRevision, tt.platforms); got != tt.want {
+				t.Errorf("isPrecached() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseFlutterRootAndEngine(t *testing.T) {
+	out := `Welcome to Flutter!
+{
+  "frameworkVersion": "3.24.5",
+  "channel": "stable",
+  "engineRevision": "a18df97ca57a249df5d8d68cd0820600223ce262",
+  "flutterRoot": "/Users/vagrant/fvm/versions/3.24.5"
+}`
+	root, engine, err := parseFlutterRootAndEngine(out)
+	if err != nil || root != "/Users/vagrant/fvm/versions/3.24.5" || engine != "a18df97ca57a249df5d8d68cd0820600223ce262" {
+		t.Errorf("parseFlutterRootAndEngine() = %s, %s, %v", root, engine, err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MetadataSourceName     = ".metadata"
+	MelosSDKPathSourceName = "melos.yaml sdkPath"
+	metadataRelPath        = ".metadata"
+	// fvmVersionsDirName is the directory of the FVM cache containing the installed versions.
+	fvmVersionsDirName = "versions"
+)
+
+// readMetadataRevision reads the framework revision from the `.metadata` file generated by `flutter create`.
+//
+// Returns an empty string if the file does not exist.
+func readMetadataRevision(projectDir string) (string, error) {
+	var metadata struct {
+		Version struct {
+			Revision string `yaml:"revision"`
+		} `yaml:"version"`
+	}
+	if err := readYAMLIfExists(filepath.Join(projectDir, metadataRelPath), &metadata); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(metadata.Version.Revision), nil
+}
+
+// metadataVersionSource maps the framework revision of the `.metadata` file to an official release.
+//
+// Returns false if the file does not exist or the revision is not a release commit (for example the project was created on master).
+func (f *FlutterInstaller) metadataVersionSource(projectDir string) (versionSource, bool, error) {
+	revision, err := readMetadataRevision(projectDir)
+	if err != nil {
+		return versionSource{}, false, err
+	}
+	if revision == "" {
+		return versionSource{}, false, nil
+	}
+
+	catalogue, err := f.releaseCatalogue()
+	if err != nil {
+		return versionSource{}, false, err
+	}
+	release, ok := catalogue.byHash(revision)
+	if !ok {
+		return versionSource{}, false, nil
+	}
+
+	return newExactVersionSource(MetadataSourceName, release.version), true, nil
+}
+
+// melosSDKPathVersion follows the `sdkPath` of melos.yaml to the FVM version it points at.
+//
+// The path is either the project's `.fvm/flutter_sdk` link or a directory of the FVM cache (`<fvm cache>/versions/<version>`).
+// Returns false if melos.yaml does not set `sdkPath`.
+func melosSDKPathVersion(projectDir string) (flutterVersion, bool, error) {
+	var melosConfig struct {
+		SDKPath string `yaml:"sdkPath"`
+	}
+	if err := readYAMLIfExists(filepath.Join(projectDir, melosConfigRelPath), &melosConfig); err != nil {
+		return flutterVersion{}, false, fmt.Errorf("read %s: %w", melosConfigRelPath, err)
+	}
+	sdkPath := strings.TrimSpace(melosConfig.SDKPath)
+	if sdkPath == "" || sdkPath == "auto" {
+		return flutterVersion{}, false, nil
+	}
+	if !filepath.IsAbs(sdkPath) {
+		sdkPath = filepath.Join(projectDir, sdkPath)
+	}
+
+	target := resolveSDKPath(sdkPath)
+	if filepath.Base(filepath.Dir(target)) != fvmVersionsDirName {
+		return flutterVersion{}, false, fmt.Errorf("sdkPath (%s) does not point to an FVM version", melosConfig.SDKPath)
+	}
+
+	version, err := parseFVMVersionString(filepath.Base(target))
+	if err != nil {
+		return flutterVersion{}, false, fmt.Errorf("parse FVM version from sdkPath (%s): %w", target, err)
+	}
+
+	return version, true, nil
+}
+
+// resolveSDKPath follows the symlinks of the SDK path.
+//
+// The `.fvm/flutter_sdk` link is usually not committed or points to another machine's FVM cache,
+// so dangling links are resolved to their target path without requiring it to exist.
+func resolveSDKPath(sdkPath string) string {
+	if target, err := filepath.EvalSymlinks(sdkPath); err == nil {
+		return target
+	}
+
+	target, err := os.Readlink(sdkPath)
+	if err != nil {
+		// Not a link: a path of the FVM cache, possibly on another machine.
+		return filepath.Clean(sdkPath)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(sdkPath), target)
+	}
+
+	return filepath.Clean(target)
+}
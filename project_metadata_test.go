@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_readMetadataRevision(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, metadataRelPath), `# This file tracks properties of this Flutter project.
+# Used by Flutter tool to assess capabilities and perform upgrades etc.
+#
+# This file should be version controlled and should not be manually edited.
+
+version:
+  revision: "761747bfc538b5af34aa0d3fac380f1bc331ec49"
+  channel: "stable"
+
+project_type: app
+`)
+
+	revision, err := readMetadataRevision(dir)
+	if err != nil {
+		t.Fatalf("readMetadataRevision() error = %v", err)
+	}
+	if revision != "761747bfc538b5af34aa0d3fac380f1bc331ec49" {
+		t.Errorf("readMetadataRevision() = %s", revision)
+	}
+
+	revision, err = readMetadataRevision(t.TempDir())
+	if err != nil || revision != "" {
+		t.Errorf("readMetadataRevision() = %s, %v, want empty result for missing file", revision, err)
+	}
+}
+
+func Test_melosSDKPathVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		sdkPath string
+		link    string
+		want    flutterVersion
+		wantOk  bool
+		wantErr bool
+	}{
+		{
+			name: "No sdkPath",
+		},
+		{
+			name:    "Project link",
+			sdkPath: ".fvm/flutter_sdk",
+			link:    "/Users/vagrant/fvm/versions/3.22.0",
+			want:    testVersion("3.22.0", "", FVMName),
+			wantOk:  true,
+		},
+		{
+			name:    "Project link to a channel",
+			sdkPath: ".fvm/flutter_sdk",
+			link:    "/Users/vagrant/fvm/versions/beta",
+			want:    testVersion("", "beta", FVMName),
+			wantOk:  true,
+		},
+		{
+			name:    "FVM cache path",
+			sdkPath: "/Users/vagrant/fvm/versions/3.24.5",
+			want:    testVersion("3.24.5", "", FVMName),
+			wantOk:  true,
+		},
+		{
+			name:    "Not an FVM path",
+			sdkPath: "/opt/flutter",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			content := "name: root\n"
+			if tt.sdkPath != "" {
+				content += "sdkPath: " + tt.sdkPath + "\n"
+			}
+			writeTestFile(t, filepath.Join(dir, melosConfigRelPath), content)
+			if tt.link != "" {
+				linkPath := filepath.Join(dir, tt.sdkPath)
+				if err := os.MkdirAll(filepath.Dir(linkPath), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(tt.link, linkPath); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, ok, err := melosSDKPathVersion(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("melosSDKPathVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOk {
+				t.Errorf("melosSDKPathVersion() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !versionsEqual(got, tt.want) {
+				t.Errorf("melosSDKPathVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
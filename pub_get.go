@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const (
+	PubGetOff             = "off"
+	PubGetOn              = "on"
+	PubGetEnforceLockfile = "enforce-lockfile"
+)
+
+var PubGetModes = []string{PubGetOff, PubGetOn, PubGetEnforceLockfile}
+
+// MelosVersion is the melos version activated if melos is not available.
+// Melos 7 reads its config from pubspec.yaml instead of melos.yaml, so the last melos.yaml based major version is used.
+const MelosVersion = "6.3.2"
+
+// pubGetCommand returns the command resolving the dependencies of the project:
+// `melos bootstrap` for melos workspaces, `flutter pub get` otherwise.
+func pubGetCommand(projectDir string, enforceLockfile bool) (string, []string) {
+	name, args := "flutter", []string{"pub", "get"}
+	if _, err := os.Stat(filepath.Join(projectDir, melosConfigRelPath)); err == nil {
+		name, args = "melos", []string{"bootstrap"}
+	}
+	if enforceLockfile {
+		args = append(args, "--enforce-lockfile")
+	}
+	return name, args
+}
+
+// ensureMelos activates melos (MelosVersion) with the installed Dart SDK if it is not available.
+func (f *FlutterInstaller) ensureMelos() error {
+	if _, err := exec.LookPath("melos"); err == nil {
+		return nil
+	}
+
+	dart, err := f.installedDart()
+	if err != nil {
+		return err
+	}
+	pubCacheDir := f.pubCacheDir()
+	if err := f.activateDartGlobalTool(dart, pubCacheDir, dartGlobalTool{name: "melos", version: MelosVersion}); err != nil {
+		return err
+	}
+
+	return f.addPubCacheBinToPath(pubCacheDir)
+}
+
+// pubGet resolves the dependencies of the project with the installed Flutter version, if enabled by the pub_get input.
+//
+// Resolution failures are reported with the installed Flutter version, as they are usually caused by its SDK constraints.
+func (f *FlutterInstaller) pubGet() error {
+	if f.Input.PubGet == PubGetOff {
+		return nil
+	}
+
+	name, args := pubGetCommand(f.Input.ProjectLocation, f.Input.PubGet == PubGetEnforceLockfile)
+	f.Infof("Resolve project dependencies")
+
+	if name == "melos" {
+		if err := f.ensureMelos(); err != nil {
+			return err
+		}
+	}
+
+	cmdOpts := command.Opts{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Dir:    f.Input.ProjectLocation,
+	}
+	cmd := f.CmdFactory.Create(name, args, &cmdOpts)
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	if err := cmd.Run(); err != nil {
+		version := "unknown"
+		if current, versionErr := f.NewFlutterVersionFromCurrent(); versionErr == nil {
+			version = f.NewVersionString(current)
+		}
+		return fmt.Errorf("%s failed with Flutter %s: %s", cmd.PrintableCommandArgs(), version, err)
+	}
+
+	return nil
+}
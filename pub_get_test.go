@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_pubGetCommand(t *testing.T) {
+	tests := []struct {
+		name            string
+		files           map[string]string
+		enforceLockfile bool
+		want            []string
+	}{
+		{
+			name:  "Flutter project",
+			files: map[string]string{pubspecRelPath: "name: app\n"},
+			want:  []string{"flutter", "pub", "get"},
+		},
+		{
+			name:            "Enforce lockfile",
+			files:           map[string]string{pubspecRelPath: "name: app\n"},
+			enforceLockfile: true,
+			want:            []string{"flutter", "pub", "get", "--enforce-lockfile"},
+		},
+		{
+			name:            "Melos workspace",
+			files:           map[string]string{pubspecRelPath: "name: root\n", melosConfigRelPath: "name: root\npackages:\n  - packages/*\n"},
+			enforceLockfile: true,
+			want:            []string{"melos", "bootstrap", "--enforce-lockfile"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for pth, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, pth), content)
+			}
+
+			name, args := pubGetCommand(dir, tt.enforceLockfile)
+			if got := append([]string{name}, args...); !slices.Equal(got, tt.want) {
+				t.Errorf("pubGetCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pubGet(t *testing.T) {
+	// melos is already activated in the pub cache, and its bin directory is on the PATH.
+	pubCacheDir := t.TempDir()
+	writeTestFile(t, filepath.Join(pubCacheDir, "global_packages", "melos", "pubspec.lock"), "packages:\n  melos:\n    version: \""+MelosVersion+"\"\n")
+	t.Setenv("PUB_CACHE", pubCacheDir)
+	t.Setenv("PATH", filepath.Join(pubCacheDir, "bin")+string(filepath.ListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name     string
+		mode     string
+		files    map[string]string
+		results  []fakeCommandResult
+		wantLast string
+		wantErr  bool
+	}{
+		{
+			name: "Disabled",
+			mode: PubGetOff,
+		},
+		{
+			name:     "Flutter project",
+			mode:     PubGetOn,
+			files:    map[string]string{pubspecRelPath: "name: app\n"},
+			wantLast: "flutter pub get",
+		},
+		{
+			name:     "Melos workspace",
+			mode:     PubGetEnforceLockfile,
+			files:    map[string]string{pubspecRelPath: "name: root\n", melosConfigRelPath: "name: root\npackages:\n  - packages/*\n"},
+			wantLast: "melos bootstrap --enforce-lockfile",
+		},
+		{
+			name:     "Resolution failure",
+			mode:     PubGetOn,
+			files:    map[string]string{pubspecRelPath: "name: app\n"},
+			results:  []fakeCommandResult{{prefix: "flutter pub get", err: errors.New("exit status 1")}},
+			wantLast: "flutter --version --machine",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for pth, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, pth), content)
+			}
+
+			factory := &fakeCommandFactory{results: tt.results}
+			err := newFakeInstaller(factory, Input{PubGet: tt.mode, ProjectLocation: dir}).pubGet()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pubGet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantLast == "" {
+				if len(factory.created) > 0 {
+					t.Errorf("pubGet() ran %q, want no commands", factory.created)
+				}
+				return
+			}
+			if len(factory.created) == 0 || factory.created[len(factory.created)-1] != tt.wantLast {
+				t.Errorf("pubGet() ran %q, want %s last", factory.created, tt.wantLast)
+			}
+			if slices.ContainsFunc(factory.created, func(c string) bool { return strings.Contains(c, "pub global activate melos") }) {
+				t.Errorf("pubGet() activated melos, which is already activated")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var envVarNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// pubToken maps a hosted pub repository to the environment variable holding its token.
+type pubToken struct {
+	hostedURL string
+	envVar    string
+}
+
+// parsePubTokens parses the `<hosted url>=<env var name>` entries of the pub_tokens input, one entry per line.
+func parsePubTokens(input string) ([]pubToken, error) {
+	var tokens []pubToken
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		separator := strings.LastIndex(line, "=")
+		if separator == -1 {
+			return nil, fmt.Errorf("invalid entry: %s, expected format: <hosted url>=<env var name>", line)
+		}
+		hostedURL, envVar := strings.TrimSpace(line[:separator]), strings.TrimPrefix(strings.TrimSpace(line[separator+1:]), "$")
+
+		parsed, err := url.Parse(hostedURL)
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid hosted URL: %s", hostedURL)
+		}
+		if parsed.Scheme != "https" && parsed.Hostname() != "localhost" {
+			return nil, fmt.Errorf("invalid hosted URL: %s, only https URLs are supported", hostedURL)
+		}
+		if !envVarNameRegexp.MatchString(envVar) {
+			return nil, fmt.Errorf("invalid environment variable name: %s", envVar)
+		}
+
+		tokens = append(tokens, pubToken{hostedURL: hostedURL, envVar: envVar})
+	}
+	return tokens, nil
+}
+
+// installedDart returns the dart executable of the installed Flutter SDK.
+//
+// A bare `dart` could resolve to another SDK (e.g. the pre-installed one), as installers like FVM
+// only add the flutter executable to the PATH.
+func (f *FlutterInstaller) installedDart() (string, error) {
+	versionCmd := f.CmdFactory.Create("flutter", []string{"--version", "--machine"}, nil)
+	out, err := versionCmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("get flutter version: %s %s", err, out)
+	}
+	flutterRoot, _, err := parseFlutterRootAndEngine(out)
+	if err != nil {
+		return "", err
+	}
+	if flutterRoot == "" {
+		return "", fmt.Errorf("no flutterRoot in output: %s", out)
+	}
+	return filepath.Join(flutterRoot, "bin", "dart"), nil
+}
+
+// addPubTokens configures the tokens of the pub_tokens input with the installed Dart SDK.
+//
+// Only the environment variable names are passed to `dart pub token add`, so the token values are never printed.
+func (f *FlutterInstaller) addPubTokens() error {
+	tokens, err := parsePubTokens(f.Input.PubTokens)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	f.Infof("Configure pub repository tokens")
+
+	dart, err := f.installedDart()
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if f.EnvRepo.Get(token.envVar) == "" {
+			f.Warnf("Environment variable %s of %s is empty, requests to the repository will fail", token.envVar, token.hostedURL)
+		}
+
+		cmd := f.CmdFactory.Create(dart, []string{"pub", "token", "add", token.hostedURL, "--env-var", token.envVar}, nil)
+		f.Donef("$ %s", cmd.PrintableCommandArgs())
+		if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+			return fmt.Errorf("add token of %s: %s %s", token.hostedURL, err, out)
+		}
+	}
+
+	return nil
+}
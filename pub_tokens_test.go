@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	logv2 "github.com/bitrise-io/go-utils/v2/log"
+)
+
+func Test_parsePubTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []pubToken
+		wantErr bool
+	}{
+		{
+			name:  "Entries",
+			input: "https://pub.example.com=PUB_TOKEN\n\nhttps://dart.cloudsmith.io/org/repo/?a=b = $CLOUDSMITH_TOKEN\n",
+			want: []pubToken{
+				{hostedURL: "https://pub.example.com", envVar: "PUB_TOKEN"},
+				{hostedURL: "https://dart.cloudsmith.io/org/repo/?a=b", envVar: "CLOUDSMITH_TOKEN"},
+			},
+		},
+		{
+			name:    "Missing env var",
+			input:   "https://pub.example.com",
+			wantErr: true,
+		},
+		{
+			name:    "Insecure URL",
+			input:   "http://pub.example.com=PUB_TOKEN",
+			wantErr: true,
+		},
+		{
+			name:    "Token value instead of env var name",
+			input:   "https://pub.example.com=abc-123.secret",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePubTokens(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePubTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parsePubTokens() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// flutterVersionMachine is the `flutter --version --machine` output of the SDK the fake commands run with.
+const flutterVersionMachine = `{
+  "frameworkVersion": "3.24.5",
+  "channel": "stable",
+  "frameworkRevision": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668",
+  "engineRevision": "a18df97ca57a249df5d8d68cd0820600223ce262",
+  "dartSdkVersion": "3.5.4",
+  "flutterRoot": "/opt/flutter"
+}`
+
+// fakeCommandResult is the output and error of the fake commands starting with the prefix.
+type fakeCommandResult struct {
+	prefix string
+	out    string
+	err    error
+}
+
+// fakeCommandFactory records the created commands instead of running them.
+//
+// A command returns the first result whose prefix it starts with, `flutter --version --machine`
+// returns flutterVersionMachine, other commands succeed without output.
+type fakeCommandFactory struct {
+	results []fakeCommandResult
+	created []string
+}
+
+func (f *fakeCommandFactory) Create(name string, args []string, _ *command.Opts) command.Command {
+	cmdLine := strings.Join(append([]string{name}, args...), " ")
+	f.created = append(f.created, cmdLine)
+
+	results := append(slices.Clone(f.results), fakeCommandResult{prefix: "flutter --version --machine", out: flutterVersionMachine})
+	for _, result := range results {
+		if strings.HasPrefix(cmdLine, result.prefix) {
+			return fakeCommand{cmdLine: cmdLine, out: result.out, err: result.err}
+		}
+	}
+	return fakeCommand{cmdLine: cmdLine}
+}
+
+type fakeCommand struct {
+	cmdLine string
+	out     string
+	err     error
+}
+
+func (c fakeCommand) PrintableCommandArgs() string { return c.cmdLine }
+func (c fakeCommand) Run() error                   { return c.err }
+func (c fakeCommand) Start() error                 { return c.err }
+func (c fakeCommand) Wait() error                  { return nil }
+
+func (c fakeCommand) RunAndReturnExitCode() (int, error) {
+	if c.err != nil {
+		return 1, c.err
+	}
+	return 0, nil
+}
+
+func (c fakeCommand) RunAndReturnTrimmedOutput() (string, error) {
+	return c.out, c.err
+}
+
+func (c fakeCommand) RunAndReturnTrimmedCombinedOutput() (string, error) {
+	return c.out, c.err
+}
+
+// newFakeInstaller returns an installer running its commands with the fake factory.
+func newFakeInstaller(factory *fakeCommandFactory, input Input) *FlutterInstaller {
+	return &FlutterInstaller{
+		Logger:     logv2.NewLogger(),
+		EnvRepo:    env.NewRepository(),
+		CmdFactory: factory,
+		Input:      input,
+	}
+}
+
+func Test_addPubTokens(t *testing.T) {
+	t.Setenv("PUB_TOKEN", "secret")
+
+	tests := []struct {
+		name        string
+		input       string
+		results     []fakeCommandResult
+		wantCreated []string
+		wantErr     bool
+	}{
+		{
+			name: "No tokens",
+		},
+		{
+			name:  "Token added with the installed Dart SDK",
+			input: "https://pub.example.com=PUB_TOKEN",
+			wantCreated: []string{
+				"flutter --version --machine",
+				"/opt/flutter/bin/dart pub token add https://pub.example.com --env-var PUB_TOKEN",
+			},
+		},
+		{
+			name:    "Failed registration",
+			input:   "https://pub.example.com=PUB_TOKEN",
+			results: []fakeCommandResult{{prefix: "/opt/flutter/bin/dart pub token add", err: errors.New("exit status 65")}},
+			wantCreated: []string{
+				"flutter --version --machine",
+				"/opt/flutter/bin/dart pub token add https://pub.example.com --env-var PUB_TOKEN",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &fakeCommandFactory{results: tt.results}
+			err := newFakeInstaller(factory, Input{PubTokens: tt.input}).addPubTokens()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addPubTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(factory.created, tt.wantCreated) {
+				t.Errorf("addPubTokens() ran %q, want %q", factory.created, tt.wantCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bitrise-io/go-flutter/fluttersdk"
+)
+
+const flutterReleasesURLFormat = "https://storage.googleapis.com/flutter_infra_release/releases/releases_%s.json"
+
+// flutterRelease is an official Flutter release available for the current platform.
+type flutterRelease struct {
+	version flutterVersion
+	// dartVersion is the version of the bundled Dart SDK, nil if it could not be parsed.
+	dartVersion *semver.Version
+	hash        string
+}
+
+// releaseCatalogue is the list of official Flutter releases available for the current platform.
+//
+// It is shared by the install types and the version resolution, instead of parsing the releases output of each tool.
+type releaseCatalogue struct {
+	releases []flutterRelease
+	// currentReleases maps the channels to the hash of their current release.
+	currentReleases map[string]string
+}
+
+// NewReleaseCatalogue loads the official Flutter releases of the current platform, using the on-disk cache if it is fresh.
+func NewReleaseCatalogue() (releaseCatalogue, error) {
+	platform, architecture := currentPlatform()
+	content, err := newReleasesCache(platform).load()
+	if err != nil {
+		return releaseCatalogue{}, fmt.Errorf("list Flutter releases: %w", err)
+	}
+
+	catalogue, err := parseReleaseCatalogue(content, platform, architecture)
+	if err != nil {
+		return releaseCatalogue{}, err
+	}
+	if len(catalogue.releases) == 0 {
+		return releaseCatalogue{}, fmt.Errorf("no Flutter releases found for %s %s", platform, architecture)
+	}
+
+	return catalogue, nil
+}
+
+// releaseCatalogueLoader loads the release catalogue on first use, at most once per run.
+type releaseCatalogueLoader struct {
+	once      sync.Once
+	catalogue releaseCatalogue
+	err       error
+}
+
+func (l *releaseCatalogueLoader) load() (releaseCatalogue, error) {
+	l.once.Do(func() {
+		l.catalogue, l.err = NewReleaseCatalogue()
+	})
+	return l.catalogue, l.err
+}
+
+// releaseCatalogue returns the release catalogue of the run, loading it on first use.
+//
+// A failed load is not retried. Only the manual install of an exact version falls back to git without it,
+// resolving constraints, .metadata revisions and workspace constraints fails, pinning a channel is skipped.
+func (f *FlutterInstaller) releaseCatalogue() (releaseCatalogue, error) {
+	if f.releases == nil {
+		f.releases = &releaseCatalogueLoader{}
+	}
+	return f.releases.load()
+}
+
+// parseReleaseCatalogue parses the releases JSON, keeping the releases built for the architecture.
+func parseReleaseCatalogue(content []byte, platform fluttersdk.Platform, architecture fluttersdk.Architecture) (releaseCatalogue, error) {
+	var resp fluttersdk.ReleasesResp
+	if err := json.Unmarshal(content, &resp); err != nil {
+		return releaseCatalogue{}, fmt.Errorf("parse Flutter releases: %w", err)
+	}
+
+	catalogue := releaseCatalogue{
+		currentReleases: map[string]string{
+			"stable": resp.CurrentRelease.Stable,
+			"beta":   resp.CurrentRelease.Beta,
+			"dev":    resp.CurrentRelease.Dev,
+		},
+	}
+	for _, release := range resp.Releases {
+		// Releases before the Apple Silicon builds do not specify the architecture.
+		arch := release.DartSdkArch
+		if arch == "" {
+			arch = string(fluttersdk.X64)
+		}
+		if platform == fluttersdk.MacOS && arch != string(architecture) {
+			continue
+		}
+
+		version, err := newFlutterVersion(release.Version, release.Channel, "")
+		if err != nil {
+			continue
+		}
+		catalogue.releases = append(catalogue.releases, flutterRelease{
+			version:     version,
+			dartVersion: parseDartSDKVersion(release.DartSdkVersion),
+			hash:        release.Hash,
+		})
+	}
+
+	return catalogue, nil
+}
+
+// versions returns the versions of the releases.
+func (c releaseCatalogue) versions() []flutterVersion {
+	var versions []flutterVersion
+	for _, release := range c.releases {
+		versions = append(versions, release.version)
+	}
+	return versions
+}
+
+// byVersion returns the release of the version, on the version's channel if it is specified.
+func (c releaseCatalogue) byVersion(version flutterVersion) (flutterRelease, bool) {
+	if version.version == nil {
+		return flutterRelease{}, false
+	}
+	for _, release := range c.releases {
+		if release.version.Compare(version) == 0 && (version.channel == "" || release.version.channel == version.channel) {
+			return release, true
+		}
+	}
+	return flutterRelease{}, false
+}
+
+// byDartVersion returns the releases bundling a Dart SDK which satisfies the constraint, latest first.
+func (c releaseCatalogue) byDartVersion(constraint *semver.Constraints) []flutterRelease {
+	var releases []flutterRelease
+	for _, release := range c.releases {
+		if release.dartVersion != nil && satisfiesConstraint(release.dartVersion, constraint) {
+			releases = append(releases, release)
+		}
+	}
+	slices.SortFunc(releases, func(a, b flutterRelease) int {
+		return b.version.Compare(a.version)
+	})
+	return releases
+}
+
+// latest returns the current release of the channel.
+func (c releaseCatalogue) latest(channel string) (flutterRelease, bool) {
+	if hash := c.currentReleases[channel]; hash != "" {
+		for _, release := range c.releases {
+			if release.hash == hash && release.version.channel == channel {
+				return release, true
+			}
+		}
+	}
+
+	var latest flutterRelease
+	found := false
+	for _, release := range c.releases {
+		if release.version.channel == channel && (!found || release.version.Compare(latest.version) > 0) {
+			latest, found = release, true
+		}
+	}
+	return latest, found
+}
+
+// byHash returns the release built from the framework revision.
+func (c releaseCatalogue) byHash(hash string) (flutterRelease, bool) {
+	for _, release := range c.releases {
+		if release.hash != "" && release.hash == hash {
+			return release, true
+		}
+	}
+	return flutterRelease{}, false
+}
+
+// parseDartSDKVersion parses Dart SDK versions like: "2.17.0 (build 2.17.0-266.1.beta)".
+func parseDartSDKVersion(dartSDKVersion string) *semver.Version {
+	fields := strings.Fields(dartSDKVersion)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	version, err := semver.NewVersion(fields[0])
+	if err != nil {
+		return nil
+	}
+	return version
+}
+
+func currentPlatform() (fluttersdk.Platform, fluttersdk.Architecture) {
+	platform := fluttersdk.Linux
+	switch runtime.GOOS {
+	case "darwin":
+		platform = fluttersdk.MacOS
+	case "windows":
+		platform = fluttersdk.Windows
+	}
+
+	architecture := fluttersdk.X64
+	if runtime.GOARCH == "arm64" {
+		architecture = fluttersdk.ARM64
+	}
+
+	return platform, architecture
+}
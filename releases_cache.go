@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bitrise-io/go-flutter/fluttersdk"
+	logv2 "github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-utils/v2/retryhttp"
+)
+
+const (
+	// releasesCacheTTL is the age until the cached releases are used without revalidation.
+	releasesCacheTTL     = 6 * time.Hour
+	releasesCacheDirName = "bitrise-step-flutter-installer"
+)
+
+// releasesCacheMeta is stored next to the cached releases JSON.
+type releasesCacheMeta struct {
+	ETag      string    `json:"etag"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// releasesCache downloads the releases JSON and caches it on disk.
+//
+// Within the TTL the cached content is used as is, after it the content is revalidated with its ETag.
+// If the download fails, the stale cache is used.
+type releasesCache struct {
+	url      string
+	cacheDir string
+	ttl      time.Duration
+	client   *http.Client
+	logger   logv2.Logger
+	now      func() time.Time
+}
+
+func newReleasesCache(platform fluttersdk.Platform) releasesCache {
+	logger := logv2.NewLogger()
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+
+	return releasesCache{
+		url:      fmt.Sprintf(flutterReleasesURLFormat, platform),
+		cacheDir: filepath.Join(cacheDir, releasesCacheDirName),
+		ttl:      releasesCacheTTL,
+		client:   retryhttp.NewClient(logger).StandardClient(),
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+func (c releasesCache) contentPath() string {
+	return filepath.Join(c.cacheDir, filepath.Base(c.url))
+}
+
+func (c releasesCache) metaPath() string {
+	return c.contentPath() + ".meta"
+}
+
+// load returns the releases JSON, from the cache if it is fresh or still valid.
+func (c releasesCache) load() ([]byte, error) {
+	content, meta, cacheErr := c.read()
+	if cacheErr == nil && c.now().Sub(meta.FetchedAt) < c.ttl {
+		c.logger.Debugf("Using cached Flutter releases from %s", meta.FetchedAt.Format(time.RFC3339))
+		return content, nil
+	}
+	if cacheErr != nil {
+		c.logger.Debugf("Flutter releases cache: %s", cacheErr)
+		meta = releasesCacheMeta{}
+	}
+
+	fetched, etag, notModified, err := c.fetch(meta.ETag)
+	if err != nil {
+		if cacheErr == nil {
+			c.logger.Warnf("Failed to download Flutter releases, using the cache from %s: %s", meta.FetchedAt.Format(time.RFC3339), err)
+			return content, nil
+		}
+		return nil, err
+	}
+	if notModified {
+		fetched = content
+	} else {
+		meta.ETag = etag
+	}
+	meta.FetchedAt = c.now()
+
+	if err := c.write(fetched, meta); err != nil {
+		c.logger.Debugf("Failed to cache Flutter releases: %s", err)
+	}
+
+	return fetched, nil
+}
+
+func (c releasesCache) read() ([]byte, releasesCacheMeta, error) {
+	var meta releasesCacheMeta
+	metaContent, err := os.ReadFile(c.metaPath())
+	if err != nil {
+		return nil, meta, err
+	}
+	if err := json.Unmarshal(metaContent, &meta); err != nil {
+		return nil, meta, err
+	}
+
+	content, err := os.ReadFile(c.contentPath())
+	if err != nil {
+		return nil, meta, err
+	}
+	return content, meta, nil
+}
+
+func (c releasesCache) write(content []byte, meta releasesCacheMeta) error {
+	if err := os.MkdirAll(c.cacheDir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.contentPath(), content, 0600); err != nil {
+		return err
+	}
+	metaContent, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.metaPath(), metaContent, 0600)
+}
+
+// fetch downloads the releases JSON, if an ETag is given, it is only downloaded if it was modified.
+func (c releasesCache) fetch(etag string) ([]byte, string, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer func(body io.ReadCloser) {
+		if err := body.Close(); err != nil {
+			c.logger.Debugf("Failed to close response body: %s", err)
+		}
+	}(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		c.logger.Debugf("Cached Flutter releases are up to date")
+		return nil, etag, true, nil
+	case http.StatusOK:
+		content, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return content, resp.Header.Get("ETag"), false, nil
+	default:
+		return nil, "", false, fmt.Errorf("download %s: status code %d", c.url, resp.StatusCode)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logv2 "github.com/bitrise-io/go-utils/v2/log"
+)
+
+func Test_releasesCache_load(t *testing.T) {
+	const etag = `"v1"`
+	requests := 0
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !available {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(releasesJSON))
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := releasesCache{
+		url:      server.URL + "/releases_macos.json",
+		cacheDir: t.TempDir(),
+		ttl:      time.Hour,
+		client:   server.Client(),
+		logger:   logv2.NewLogger(),
+		now:      func() time.Time { return now },
+	}
+
+	load := func(wantRequests int) {
+		t.Helper()
+		content, err := cache.load()
+		if err != nil {
+			t.Fatalf("load() error = %v", err)
+		}
+		if string(content) != releasesJSON {
+			t.Errorf("load() = %s, want releases JSON", content)
+		}
+		if requests != wantRequests {
+			t.Errorf("load() made %d requests, want %d", requests, wantRequests)
+		}
+	}
+
+	// Empty cache: downloaded.
+	load(1)
+	// Fresh cache: not revalidated.
+	now = now.Add(30 * time.Minute)
+	load(1)
+	// Stale cache: revalidated with the ETag, not modified.
+	now = now.Add(time.Hour)
+	load(2)
+	// Revalidation refreshed the cache.
+	now = now.Add(30 * time.Minute)
+	load(2)
+	// Download fails: stale cache is used.
+	available = false
+	now = now.Add(2 * time.Hour)
+	load(3)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-flutter/fluttersdk"
+)
+
+const releasesJSON = `{
+  "base_url": "https://storage.googleapis.com/flutter_infra_release/releases",
+  "current_release": {
+    "beta": "e9b6c3b2f3e8b7a5e4d3c2b1a0f9e8d7c6b5a4f3",
+    "dev": "",
+    "stable": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668"
+  },
+  "releases": [
+    {"hash": "e9b6c3b2f3e8b7a5e4d3c2b1a0f9e8d7c6b5a4f3", "channel": "beta", "version": "3.27.0-0.1.pre", "dart_sdk_version": "3.6.0 (build 3.6.0-216.1.beta)", "dart_sdk_arch": "arm64"},
+    {"hash": "e9b6c3b2f3e8b7a5e4d3c2b1a0f9e8d7c6b5a4f3", "channel": "beta", "version": "3.27.0-0.1.pre", "dart_sdk_version": "3.6.0 (build 3.6.0-216.1.beta)", "dart_sdk_arch": "x64"},
+    {"hash": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668", "channel": "stable", "version": "3.24.5", "dart_sdk_version": "3.5.4", "dart_sdk_arch": "arm64"},
+    {"hash": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668", "channel": "stable", "version": "3.24.5", "dart_sdk_version": "3.5.4", "dart_sdk_arch": "x64"},
+    {"hash": "b0850beeb25f6d5b10426284f506557f66181b36", "channel": "stable", "version": "3.22.3", "dart_sdk_version": "3.4.4", "dart_sdk_arch": "x64"},
+    {"hash": "8661d8aecd626f7f57ccbcb735553edc05a2e713", "channel": "stable", "version": "v1.2.1", "dart_sdk_version": "2.1.2"}
+  ]
+}`
+
+func testReleaseCatalogue(t *testing.T, architecture fluttersdk.Architecture) releaseCatalogue {
+	t.Helper()
+	catalogue, err := parseReleaseCatalogue([]byte(releasesJSON), fluttersdk.MacOS, architecture)
+	if err != nil {
+		t.Fatalf("parseReleaseCatalogue() error = %v", err)
+	}
+	return catalogue
+}
+
+func Test_parseReleaseCatalogue(t *testing.T) {
+	tests := []struct {
+		name         string
+		architecture fluttersdk.Architecture
+		want         []string
+	}{
+		{
+			name:         "Apple Silicon",
+			architecture: fluttersdk.ARM64,
+			want:         []string{"3.27.0-0.1.pre", "3.24.5"},
+		},
+		{
+			name:         "Intel, releases without architecture",
+			architecture: fluttersdk.X64,
+			want:         []string{"3.27.0-0.1.pre", "3.24.5", "3.22.3", "v1.2.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, version := range testReleaseCatalogue(t, tt.architecture).versions() {
+				got = append(got, version.versionString())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("versions() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("versions() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func Test_releaseCatalogue_queries(t *testing.T) {
+	catalogue := testReleaseCatalogue(t, fluttersdk.X64)
+
+	if release, ok := catalogue.byVersion(testVersion("3.22.3", "", "")); !ok || release.version.channel != "stable" {
+		t.Errorf("byVersion(3.22.3) = %v, %v, want stable release", release.version, ok)
+	}
+	if _, ok := catalogue.byVersion(testVersion("3.22.3", "beta", "")); ok {
+		t.Errorf("byVersion(3.22.3 beta) found a release on another channel")
+	}
+	if release, ok := catalogue.byVersion(testVersion("1.2.1", "", "")); !ok || release.hash != "8661d8aecd626f7f57ccbcb735553edc05a2e713" {
+		t.Errorf("byVersion(1.2.1) = %v, %v, want v1.2.1 release", release.version, ok)
+	}
+
+	if release, ok := catalogue.latest("stable"); !ok || release.version.versionString() != "3.24.5" {
+		t.Errorf("latest(stable) = %v, %v, want 3.24.5", release.version, ok)
+	}
+	if release, ok := catalogue.latest("beta"); !ok || release.version.versionString() != "3.27.0-0.1.pre" {
+		t.Errorf("latest(beta) = %v, %v, want 3.27.0-0.1.pre", release.version, ok)
+	}
+	if _, ok := catalogue.latest("dev"); ok {
+		t.Errorf("latest(dev) found a release")
+	}
+
+	releases := catalogue.byDartVersion(mustConstraint(t, ">=3.4.0 <3.6.0"))
+	if len(releases) != 2 || releases[0].version.versionString() != "3.24.5" || releases[1].version.versionString() != "3.22.3" {
+		t.Errorf("byDartVersion(>=3.4.0 <3.6.0) = %v, want 3.24.5, 3.22.3", releases)
+	}
+
+	if release, ok := catalogue.byHash("b0850beeb25f6d5b10426284f506557f66181b36"); !ok || release.version.versionString() != "3.22.3" {
+		t.Errorf("byHash() = %v, %v, want 3.22.3", release.version, ok)
+	}
+	if _, ok := catalogue.byHash("0000000000000000000000000000000000000000"); ok {
+		t.Errorf("byHash() found a release for an unknown revision")
+	}
+}
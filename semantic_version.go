@@ -0,0 +1,99 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const hotfixIdentifier = "hotfix"
+
+// parseSemanticVersion parses a Flutter version string into a semantic version.
+//
+// Flutter versions are semantic versions with a few special forms:
+//   - legacy tags have a `v` prefix (v1.2.1),
+//   - hotfix releases have a `-hotfix.N` pre-release or `+hotfix.N` build suffix (v1.5.4-hotfix.2, 3.7.12+hotfix.1),
+//   - beta (earlier dev) builds have a `-N.M.pre` suffix (3.33.0-0.2.pre).
+//
+// The original form is kept, so it can be passed to tools expecting the same format.
+func parseSemanticVersion(version string) (*semver.Version, error) {
+	return semver.NewVersion(strings.TrimSpace(version))
+}
+
+// compareSemanticVersions orders Flutter versions.
+//
+// It follows semantic versioning, except hotfix releases which are ordered after the version they fix.
+func compareSemanticVersions(a, b *semver.Version) int {
+	coreA := semver.New(a.Major(), a.Minor(), a.Patch(), corePrerelease(a), "")
+	coreB := semver.New(b.Major(), b.Minor(), b.Patch(), corePrerelease(b), "")
+	if c := coreA.Compare(coreB); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(hotfixNumber(a), hotfixNumber(b))
+}
+
+// satisfiesConstraint checks the version against the constraint.
+//
+// Hotfix releases are checked as the version they fix, so `1.5.4-hotfix.2` satisfies `>=1.5.4`.
+func satisfiesConstraint(v *semver.Version, constraint *semver.Constraints) bool {
+	if hotfixNumber(v) > 0 {
+		v = semver.New(v.Major(), v.Minor(), v.Patch(), "", "")
+	}
+	return constraint.Check(v)
+}
+
+// mustParseConstraint parses a constraint of the Step's version tables, panics if it is invalid.
+//
+// The tables are parsed at package load, so an invalid entry fails every test run instead of a build.
+func mustParseConstraint(c string) *semver.Constraints {
+	constraint, err := semver.NewConstraint(c)
+	if err != nil {
+		panic(fmt.Sprintf("invalid version constraint %s: %s", c, err))
+	}
+	return constraint
+}
+
+// inferChannel guesses the release channel from the version form.
+//
+// It is used when the tool reports `unknown` channel (e.g. when installed from an archive):
+// pre-release builds are published on the beta channel, other releases on the stable channel.
+func inferChannel(v *semver.Version) string {
+	if v == nil {
+		return ""
+	}
+	if strings.HasSuffix(v.Prerelease(), ".pre") {
+		return "beta"
+	}
+	if v.Prerelease() == "" || hotfixNumber(v) > 0 {
+		return "stable"
+	}
+	return ""
+}
+
+// corePrerelease returns the pre-release part of the version which is not a hotfix suffix.
+func corePrerelease(v *semver.Version) string {
+	if strings.HasPrefix(v.Prerelease(), hotfixIdentifier) {
+		return ""
+	}
+	return v.Prerelease()
+}
+
+// hotfixNumber returns the hotfix number of the version, 0 if it is not a hotfix release.
+func hotfixNumber(v *semver.Version) int {
+	for _, suffix := range []string{v.Prerelease(), v.Metadata()} {
+		if !strings.HasPrefix(suffix, hotfixIdentifier) {
+			continue
+		}
+
+		number, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(suffix, hotfixIdentifier), "."))
+		if err != nil {
+			return 1
+		}
+		return number
+	}
+	return 0
+}
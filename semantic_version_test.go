@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func Test_compareSemanticVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{
+			name: "v prefix",
+			a:    "v3.7.12",
+			b:    "3.7.12",
+			want: 0,
+		},
+		{
+			name: "Patch ordering",
+			a:    "3.7.9",
+			b:    "3.7.12",
+			want: -1,
+		},
+		{
+			name: "Hotfix after fixed version",
+			a:    "1.5.4-hotfix.2",
+			b:    "1.5.4",
+			want: 1,
+		},
+		{
+			name: "Hotfix ordering",
+			a:    "v1.5.4-hotfix.1",
+			b:    "1.5.4-hotfix.2",
+			want: -1,
+		},
+		{
+			name: "Hotfix build metadata",
+			a:    "3.7.12+hotfix.1",
+			b:    "3.7.12",
+			want: 1,
+		},
+		{
+			name: "Hotfix before next patch",
+			a:    "1.5.4-hotfix.2",
+			b:    "1.5.5",
+			want: -1,
+		},
+		{
+			name: "Pre-release before release",
+			a:    "3.33.0-0.2.pre",
+			b:    "3.33.0",
+			want: -1,
+		},
+		{
+			name: "Pre-release ordering",
+			a:    "3.33.0-0.2.pre",
+			b:    "3.33.0-0.1.pre",
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := semver.MustParse(tt.a), semver.MustParse(tt.b)
+			if got := compareSemanticVersions(a, b); got != tt.want {
+				t.Errorf("compareSemanticVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_satisfiesConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		constraint string
+		want       bool
+	}{
+		{
+			name:       "Wildcard",
+			version:    "v3.24.5",
+			constraint: "3.24.x",
+			want:       true,
+		},
+		{
+			name:       "Hotfix satisfies fixed version",
+			version:    "1.5.4-hotfix.2",
+			constraint: ">=1.5.4",
+			want:       true,
+		},
+		{
+			name:       "Pre-release excluded from release constraint",
+			version:    "3.33.0-0.2.pre",
+			constraint: ">=3.32.0",
+			want:       false,
+		},
+		{
+			name:       "Pre-release included in pre-release constraint",
+			version:    "3.33.0-0.2.pre",
+			constraint: ">=3.33.0-0",
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := semver.NewConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("semver.NewConstraint() error = %v", err)
+			}
+			if got := satisfiesConstraint(semver.MustParse(tt.version), constraint); got != tt.want {
+				t.Errorf("satisfiesConstraint(%s, %s) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inferChannel(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "3.32.5", want: "stable"},
+		{version: "v1.5.4-hotfix.2", want: "stable"},
+		{version: "3.7.12+hotfix.1", want: "stable"},
+		{version: "3.33.0-0.2.pre", want: "beta"},
+		{version: "1.7.1-pre.49", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := inferChannel(semver.MustParse(tt.version)); got != tt.want {
+				t.Errorf("inferChannel(%s) = %s, want %s", tt.version, got, tt.want)
+			}
+		})
+	}
+}
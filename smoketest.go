@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const smokeTestProjectName = "installer_smoke_test"
+
+// smokeTestError is returned if the installed SDK fails the smoke test, reported separately from installation errors.
+type smokeTestError struct {
+	stage string
+	err   error
+}
+
+func (e smokeTestError) Error() string {
+	return fmt.Sprintf("smoke test failed at %s: %s", e.stage, e.err)
+}
+
+func (e smokeTestError) Unwrap() error {
+	return e.err
+}
+
+// smokeTestCreateArgs returns the `flutter create` arguments of the throwaway project,
+// resolving its dependencies from the pub cache if the offline flag is supported.
+func smokeTestCreateArgs(projectDir string, offline bool) []string {
+	args := []string{"create", "--project-name", smokeTestProjectName}
+	if offline {
+		args = append(args, "--offline")
+	}
+	return append(args, projectDir)
+}
+
+// runSmokeTest checks that the installed SDK is able to compile: it creates a throwaway project in a temporary directory,
+// then runs `flutter analyze` and `flutter test` in it.
+//
+// Failures are returned as smokeTestError.
+func (f *FlutterInstaller) runSmokeTest() error {
+	f.Infof("Smoke test the installed Flutter SDK")
+
+	tmpDir, err := os.MkdirTemp("", "flutter-smoke-test")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			f.Debugf("Failed to remove smoke test project: %s", err)
+		}
+	}()
+	projectDir := filepath.Join(tmpDir, smokeTestProjectName)
+
+	helpCmd := f.CmdFactory.Create("flutter", []string{"create", "--help"}, nil)
+	helpOut, err := helpCmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		f.Debugf("flutter create --help: %s", err)
+	}
+	offline := strings.Contains(helpOut, "--offline")
+
+	stages := []struct {
+		name string
+		args []string
+		dir  string
+	}{
+		{name: "create", args: smokeTestCreateArgs(projectDir, offline)},
+		{name: "analyze", args: []string{"analyze"}, dir: projectDir},
+		{name: "test", args: []string{"test"}, dir: projectDir},
+	}
+
+	start := time.Now()
+	for _, stage := range stages {
+		stageStart := time.Now()
+		out, err := f.runSmokeTestStage(stage.args, stage.dir)
+		if err != nil && stage.name == "create" && offline {
+			// The packages of the template might be missing from the pub cache.
+			f.Debugf("Offline create failed, retrying online: %s", out)
+			out, err = f.runSmokeTestStage(smokeTestCreateArgs(projectDir, false), "")
+		}
+		if err != nil {
+			f.Printf("%s", out)
+			return smokeTestError{stage: "flutter " + stage.name, err: err}
+		}
+		f.Printf("flutter %s: %s", stage.name, time.Since(stageStart).Round(time.Millisecond))
+	}
+	f.Donef("Smoke test passed in %s", time.Since(start).Round(time.Second))
+
+	return nil
+}
+
+func (f *FlutterInstaller) runSmokeTestStage(args []string, dir string) (string, error) {
+	cmd := f.CmdFactory.Create("flutter", args, &command.Opts{Dir: dir})
+	f.Donef("$ %s", cmd.PrintableCommandArgs())
+	return cmd.RunAndReturnTrimmedCombinedOutput()
+}
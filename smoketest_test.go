@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_smokeTestCreateArgs(t *testing.T) {
+	if got, want := smokeTestCreateArgs("/tmp/p", true), []string{"create", "--project-name", smokeTestProjectName, "--offline", "/tmp/p"}; !slices.Equal(got, want) {
+		t.Errorf("smokeTestCreateArgs(offline) = %v, want %v", got, want)
+	}
+	if got, want := smokeTestCreateArgs("/tmp/p", false), []string{"create", "--project-name", smokeTestProjectName, "/tmp/p"}; !slices.Equal(got, want) {
+		t.Errorf("smokeTestCreateArgs() = %v, want %v", got, want)
+	}
+}
+
+func Test_smokeTestError(t *testing.T) {
+	cause := errors.New("exit status 1")
+	err := fmt.Errorf("execute: %w", smokeTestError{stage: "flutter test", err: cause})
+
+	var smokeErr smokeTestError
+	if !errors.As(err, &smokeErr) || smokeErr.stage != "flutter test" {
+		t.Errorf("errors.As() did not find the smoke test error in %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is() did not find the cause in %v", err)
+	}
+}
+
+func Test_runSmokeTest(t *testing.T) {
+	createFailed := errors.New("exit status 69")
+	analyzeFailed := errors.New("exit status 1")
+
+	tests := []struct {
+		name        string
+		results     []fakeCommandResult
+		wantCreated []string
+		wantStage   string
+		wantErr     error
+	}{
+		{
+			name: "Passed",
+			wantCreated: []string{
+				"flutter create --help",
+				"flutter create --project-name installer_smoke_test <dir>",
+				"flutter analyze",
+				"flutter test",
+			},
+		},
+		{
+			name: "Offline create retried online",
+			results: []fakeCommandResult{
+				{prefix: "flutter create --help", out: "--offline    When this option is on, the project is created offline."},
+				{prefix: "flutter create --project-name installer_smoke_test --offline", err: createFailed},
+			},
+			wantCreated: []string{
+				"flutter create --help",
+				"flutter create --project-name installer_smoke_test --offline <dir>",
+				"flutter create --project-name installer_smoke_test <dir>",
+				"flutter analyze",
+				"flutter test",
+			},
+		},
+		{
+			name:    "Failed analyze",
+			results: []fakeCommandResult{{prefix: "flutter analyze", out: "1 issue found.", err: analyzeFailed}},
+			wantCreated: []string{
+				"flutter create --help",
+				"flutter create --project-name installer_smoke_test <dir>",
+				"flutter analyze",
+			},
+			wantStage: "flutter analyze",
+			wantErr:   analyzeFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &fakeCommandFactory{results: tt.results}
+			err := newFakeInstaller(factory, Input{}).runSmokeTest()
+
+			var smokeErr smokeTestError
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("runSmokeTest() error = %v", err)
+			}
+			if tt.wantErr != nil && (!errors.As(err, &smokeErr) || smokeErr.stage != tt.wantStage || !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("runSmokeTest() error = %v, want failure at %s", err, tt.wantStage)
+			}
+
+			// The throwaway project is created in a temporary directory.
+			var created []string
+			for _, cmdLine := range factory.created {
+				fields := strings.Fields(cmdLine)
+				if last := len(fields) - 1; filepath.IsAbs(fields[last]) {
+					fields[last] = "<dir>"
+				}
+				created = append(created, strings.Join(fields, " "))
+			}
+			if !slices.Equal(created, tt.wantCreated) {
+				t.Errorf("runSmokeTest() ran %q, want %q", created, tt.wantCreated)
+			}
+		})
+	}
+}
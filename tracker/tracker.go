@@ -1,6 +1,7 @@
 package tracker
 
 import (
+	"github.com/Masterminds/semver/v3"
 	"github.com/bitrise-io/go-flutter/flutterproject"
 	"github.com/bitrise-io/go-utils/v2/analytics"
 	"github.com/bitrise-io/go-utils/v2/env"
@@ -19,8 +20,20 @@ func NewStepTracker(logger log.Logger, envRepo env.Repository) StepTracker {
 	}
 }
 
-func (t *StepTracker) LogSDKVersions(projectSDKVersions flutterproject.FlutterAndDartSDKVersions) {
+// AdditionalSDKVersions are the Flutter versions read from project files not handled by the flutterproject package.
+type AdditionalSDKVersions struct {
+	MetadataFlutterVersion     *semver.Version
+	MelosSDKPathFlutterVersion *semver.Version
+}
+
+func (t *StepTracker) LogSDKVersions(projectSDKVersions flutterproject.FlutterAndDartSDKVersions, additionalSDKVersions AdditionalSDKVersions) {
 	p := projectSDKVersionsToProperties(projectSDKVersions)
+	if additionalSDKVersions.MetadataFlutterVersion != nil {
+		p["flutter_sdk_metadata"] = additionalSDKVersions.MetadataFlutterVersion.String()
+	}
+	if additionalSDKVersions.MelosSDKPathFlutterVersion != nil {
+		p["flutter_sdk_melos_sdk_path"] = additionalSDKVersions.MelosSDKPathFlutterVersion.String()
+	}
 	t.tracker.Enqueue("step_flutter_installer_project_sdk_versions", p)
 }
 
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-steputils/tools"
+)
+
+const (
+	VersionLockRelPath = ".flutter-installer.lock"
+
+	ResolvedVersionOutputKey  = "FLUTTER_RESOLVED_VERSION"
+	ResolvedRevisionOutputKey = "FLUTTER_RESOLVED_REVISION"
+)
+
+// versionLock is the content of the lock file, the releases the channels were pinned to.
+type versionLock struct {
+	Channels map[string]lockedRelease `json:"channels"`
+}
+
+type lockedRelease struct {
+	Version string `json:"version"`
+	Hash    string `json:"hash"`
+}
+
+// readVersionLock reads the lock file, returns an empty lock if it does not exist.
+func readVersionLock(pth string) (versionLock, error) {
+	lock := versionLock{Channels: map[string]lockedRelease{}}
+
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return lock, err
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return lock, fmt.Errorf("parse %s: %w", pth, err)
+	}
+	if lock.Channels == nil {
+		lock.Channels = map[string]lockedRelease{}
+	}
+
+	return lock, nil
+}
+
+func writeVersionLock(pth string, lock versionLock) error {
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(pth, append(content, '\n'), 0644)
+}
+
+// pinnedSpecifier returns the exact specifier of the release a channel was pinned to.
+//
+// The channel is not kept, so that install types cache the release by its version.
+func pinnedSpecifier(release flutterRelease) versionSpecifier {
+	version := release.version
+	version.channel = ""
+	return newVersionSpecifierFromVersion(version, version.versionString())
+}
+
+// releaseFor returns the pinned release of the channel from the lock, if the channel is locked.
+func (l versionLock) releaseFor(channel string) (flutterRelease, bool, error) {
+	locked, ok := l.Channels[channel]
+	if !ok {
+		return flutterRelease{}, false, nil
+	}
+
+	version, err := newFlutterVersion(locked.Version, channel, "")
+	if err != nil {
+		return flutterRelease{}, false, fmt.Errorf("locked %s version: %w", channel, err)
+	}
+	if version.version == nil {
+		return flutterRelease{}, false, fmt.Errorf("no version locked for channel %s", channel)
+	}
+
+	return flutterRelease{version: version, hash: locked.Hash}, true, nil
+}
+
+// verifyLockedRelease checks that the locked release is an official release of its channel, built from the locked revision.
+func verifyLockedRelease(locked flutterRelease, catalogue releaseCatalogue) error {
+	release, ok := catalogue.byVersion(locked.version)
+	if !ok {
+		return fmt.Errorf("%s is not a release of the %s channel", locked.version.versionString(), locked.version.channel)
+	}
+	if locked.hash != "" && locked.hash != release.hash {
+		return fmt.Errorf("locked %s revision %s does not match the released revision %s", locked.version.versionString(), locked.hash, release.hash)
+	}
+	return nil
+}
+
+// pinChannels resolves the channel only specifiers to the current release of the channel,
+// so the installed SDK does not depend on the day the build ran.
+//
+// If the lock file is enabled, channels are pinned to the locked releases, and channels missing from the lock are added to it.
+// Locked releases are verified against the official releases, if they can be listed.
+// Channels without official releases (e.g. main) are not pinned.
+func (f *FlutterInstaller) pinChannels(specifiers []versionSpecifier) ([]versionSpecifier, error) {
+	if f.Input.BundleURL != "" {
+		return specifiers, nil
+	}
+
+	lockPath := filepath.Join(f.Input.ProjectLocation, VersionLockRelPath)
+	lock := versionLock{Channels: map[string]lockedRelease{}}
+	if f.Input.UseVersionLock {
+		var err error
+		if lock, err = readVersionLock(lockPath); err != nil {
+			return nil, fmt.Errorf("read version lock: %w", err)
+		}
+	}
+
+	lockUpdated := false
+	var pinned []versionSpecifier
+	var resolved *flutterRelease
+	for _, specifier := range specifiers {
+		if !specifier.isChannelOnly() {
+			pinned = append(pinned, specifier)
+			continue
+		}
+		channel := specifier.version.channel
+
+		release, locked, err := lock.releaseFor(channel)
+		if err != nil {
+			return nil, fmt.Errorf("read version lock: %w", err)
+		}
+		if locked {
+			if catalogue, err := f.releaseCatalogue(); err != nil {
+				f.Warnf("Failed to list Flutter releases, not verifying the locked %s release: %s", channel, err)
+			} else if err := verifyLockedRelease(release, catalogue); err != nil {
+				return nil, fmt.Errorf("verify %s: %w", VersionLockRelPath, err)
+			}
+			f.Infof("%s → %s (%s), locked in %s", channel, release.version.versionString(), release.hash, VersionLockRelPath)
+		} else {
+			catalogue, err := f.releaseCatalogue()
+			if err != nil {
+				f.Warnf("Failed to list Flutter releases, not pinning channel %s: %s", channel, err)
+				pinned = append(pinned, specifier)
+				continue
+			}
+
+			var found bool
+			if release, found = catalogue.latest(channel); !found {
+				f.Debugf("No release found on channel %s, not pinning it", channel)
+				pinned = append(pinned, specifier)
+				continue
+			}
+			f.Infof("%s → %s (%s)", channel, release.version.versionString(), release.hash)
+
+			if f.Input.UseVersionLock {
+				lock.Channels[channel] = lockedRelease{Version: release.version.versionString(), Hash: release.hash}
+				lockUpdated = true
+			}
+		}
+
+		if resolved == nil {
+			resolved = &release
+		}
+		pinned = append(pinned, pinnedSpecifier(release))
+	}
+
+	if lockUpdated {
+		if err := writeVersionLock(lockPath, lock); err != nil {
+			return nil, fmt.Errorf("write version lock: %w", err)
+		}
+		f.Printf("Pinned releases written to %s, commit it to reuse them in subsequent builds", lockPath)
+	}
+
+	if resolved != nil {
+		for key, value := range map[string]string{
+			ResolvedVersionOutputKey:  resolved.version.versionString(),
+			ResolvedRevisionOutputKey: resolved.hash,
+		} {
+			if err := tools.ExportEnvironmentWithEnvman(key, value); err != nil {
+				f.Warnf("Failed to export %s: %s", key, err)
+			}
+		}
+	}
+
+	return pinned, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-flutter/fluttersdk"
+)
+
+func Test_readVersionLock(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		channel     string
+		wantVersion string
+		wantLocked  bool
+		wantErr     bool
+	}{
+		{
+			name:        "Locked channel",
+			content:     `{"channels": {"stable": {"version": "3.24.5", "hash": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668"}}}`,
+			channel:     "stable",
+			wantVersion: "3.24.5",
+			wantLocked:  true,
+		},
+		{
+			name:    "Channel not locked",
+			content: `{"channels": {"stable": {"version": "3.24.5"}}}`,
+			channel: "beta",
+		},
+		{
+			name:    "Empty lock",
+			content: `{}`,
+			channel: "stable",
+		},
+		{
+			name:    "Missing version",
+			content: `{"channels": {"stable": {"hash": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668"}}}`,
+			channel: "stable",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), VersionLockRelPath)
+			if err := os.WriteFile(pth, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			lock, err := readVersionLock(pth)
+			if err != nil {
+				t.Fatalf("readVersionLock() error = %v", err)
+			}
+			release, locked, err := lock.releaseFor(tt.channel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("releaseFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if locked != tt.wantLocked {
+				t.Fatalf("releaseFor() locked = %v, want %v", locked, tt.wantLocked)
+			}
+			if locked && release.version.versionString() != tt.wantVersion {
+				t.Errorf("releaseFor() = %s, want %s", release.version.versionString(), tt.wantVersion)
+			}
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		lock, err := readVersionLock(filepath.Join(t.TempDir(), VersionLockRelPath))
+		if err != nil || len(lock.Channels) != 0 {
+			t.Errorf("readVersionLock() = %v, %v, want empty lock", lock, err)
+		}
+	})
+
+	t.Run("Written lock is read back", func(t *testing.T) {
+		pth := filepath.Join(t.TempDir(), VersionLockRelPath)
+		want := versionLock{Channels: map[string]lockedRelease{"beta": {Version: "3.27.0-0.1.pre", Hash: "e9b6c3b2"}}}
+		if err := writeVersionLock(pth, want); err != nil {
+			t.Fatalf("writeVersionLock() error = %v", err)
+		}
+		got, err := readVersionLock(pth)
+		if err != nil || got.Channels["beta"] != want.Channels["beta"] {
+			t.Errorf("readVersionLock() = %v, %v, want %v", got, err, want)
+		}
+	})
+}
+
+func Test_verifyLockedRelease(t *testing.T) {
+	catalogue := testReleaseCatalogue(t, fluttersdk.X64)
+
+	tests := []struct {
+		name    string
+		version string
+		channel string
+		hash    string
+		wantErr bool
+	}{
+		{
+			name:    "Matching revision",
+			version: "3.24.5",
+			channel: "stable",
+			hash:    "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668",
+		},
+		{
+			name:    "No revision locked",
+			version: "3.22.3",
+			channel: "stable",
+		},
+		{
+			name:    "Different revision",
+			version: "3.24.5",
+			channel: "stable",
+			hash:    "b0850beeb25f6d5b10426284f506557f66181b36",
+			wantErr: true,
+		},
+		{
+			name:    "Not a release of the channel",
+			version: "3.24.5",
+			channel: "beta",
+			hash:    "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locked := flutterRelease{version: testVersion(tt.version, tt.channel, ""), hash: tt.hash}
+			if err := verifyLockedRelease(locked, catalogue); (err != nil) != tt.wantErr {
+				t.Errorf("verifyLockedRelease() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_pinnedSpecifier(t *testing.T) {
+	release, _ := testReleaseCatalogue(t, fluttersdk.X64).latest("stable")
+	specifier := pinnedSpecifier(release)
+
+	if specifier.isChannelOnly() || specifier.String() != "3.24.5" {
+		t.Errorf("pinnedSpecifier() = %s, want exact version 3.24.5", specifier)
+	}
+	if !specifier.matches(testVersion("3.24.5", "stable", "")) {
+		t.Errorf("pinnedSpecifier() does not match the pinned release")
+	}
+	if specifier.matches(testVersion("3.22.3", "stable", "")) {
+		t.Errorf("pinnedSpecifier() matches another release of the channel")
+	}
+}
+
+func Test_versionSpecifier_isChannelOnly(t *testing.T) {
+	tests := []struct {
+		specifier string
+		want      bool
+	}{
+		{specifier: "stable", want: true},
+		{specifier: "3.24.5"},
+		{specifier: "3.24.5 stable"},
+		{specifier: "3.24.x"},
+		{specifier: "mycompany/3.22.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.specifier, func(t *testing.T) {
+			s, err := newVersionSpecifier(tt.specifier)
+			if err != nil {
+				t.Fatalf("newVersionSpecifier() error = %v", err)
+			}
+			if got := s.isChannelOnly(); got != tt.want {
+				t.Errorf("isChannelOnly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
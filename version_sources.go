@@ -0,0 +1,376 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bitrise-io/go-flutter/flutterproject"
+	"github.com/bitrise-io/go-flutter/fluttersdk"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/bitrise-io/go-utils/v2/fileutil"
+	logv2 "github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-utils/v2/pathutil"
+	"github.com/bitrise-steplib/bitrise-step-flutter-installer/tracker"
+)
+
+const (
+	InputSourceName          = "version input"
+	FVMRCSourceName          = ".fvmrc"
+	FVMConfigSourceName      = ".fvm/fvm_config.json"
+	ASDFSourceName           = ".tool-versions"
+	PubspecLockSourceName    = "pubspec.lock"
+	PubspecSourceName        = "pubspec.yaml"
+	fvmrcRelPath             = ".fvmrc"
+	versionSourceTableFormat = "%-24s %-24s %s"
+)
+
+// DefaultVersionSourcePriority is the precedence of the version sources if the version_source_priority input is not set.
+var DefaultVersionSourcePriority = []string{"input", "fvm", "tool-versions", "workspace", "pubspec", "melos", "metadata"}
+
+// versionSourcePriorityKeys maps the keys of the version_source_priority input to the sources they select, in order.
+var versionSourcePriorityKeys = map[string][]string{
+	"input":         {InputSourceName},
+	"fvm":           {FVMRCSourceName, FVMConfigSourceName},
+	"tool-versions": {ASDFSourceName},
+	"workspace":     {WorkspaceSourceName},
+	"pubspec":       {PubspecLockSourceName, PubspecSourceName},
+	"melos":         {MelosSDKPathSourceName},
+	"metadata":      {MetadataSourceName},
+}
+
+// inferredVersionSourceNames are the sources which do not declare a version requirement,
+// the version is derived from other project data (the SDK the project was created with, or the SDK constraints of the packages).
+var inferredVersionSourceNames = []string{WorkspaceSourceName, MelosSDKPathSourceName, MetadataSourceName}
+
+// versionSource is a Flutter version requirement and the file (or input) declaring it.
+type versionSource struct {
+	name      string
+	specifier versionSpecifier
+}
+
+// inferred reports whether the version is derived from other project data instead of being declared.
+func (s versionSource) inferred() bool {
+	return slices.Contains(inferredVersionSourceNames, s.name)
+}
+
+// versionSourceConflict describes two sources declaring incompatible Flutter versions.
+type versionSourceConflict struct {
+	a versionSource
+	b versionSource
+}
+
+func (c versionSourceConflict) String() string {
+	return fmt.Sprintf("%s requires %s, but %s requires %s", c.a.name, c.a.specifier, c.b.name, c.b.specifier)
+}
+
+// parseProjectConfigFiles collects the Flutter versions declared in the project configuration files.
+//
+// The sources are returned in priority order: fvm, asdf, pubspec.lock, pubspec.yaml, melos sdkPath.
+// The melos sdkPath source is best effort, failing to read it is not an error.
+// The `.metadata` source is resolved separately (see metadataVersionSource), as it requires the release catalogue.
+func (f *FlutterInstaller) parseProjectConfigFiles(projectDir string) ([]versionSource, flutterproject.FlutterAndDartSDKVersions, error) {
+	proj, err := flutterproject.New(projectDir, fileutil.NewFileManager(), pathutil.NewPathChecker(), fluttersdk.NewSDKVersionFinder())
+	if err != nil {
+		return nil, flutterproject.FlutterAndDartSDKVersions{}, fmt.Errorf("open project: %s", err)
+	}
+	sdkVersions, err := proj.FlutterAndDartSDKVersions()
+	if err != nil {
+		return nil, flutterproject.FlutterAndDartSDKVersions{}, fmt.Errorf("get Flutter and Dart SDK versions: %s", err)
+	}
+	var sources []versionSource
+
+	fvmrcVersion, err := readFVMRCVersion(projectDir, f.Input.FVMFlavor)
+	if err != nil {
+		return nil, sdkVersions, fmt.Errorf("read %s: %w", FVMRCSourceName, err)
+	}
+	if fvmrcVersion.version != nil || fvmrcVersion.channel != "" {
+		sources = append(sources, newExactVersionSource(FVMRCSourceName, fvmrcVersion))
+	}
+
+	if sdkVersions.FVMFlutterVersion != nil || sdkVersions.FVMFlutterChannel != "" {
+		sources = append(sources, newExactVersionSource(FVMConfigSourceName, flutterVersion{
+			version:     sdkVersions.FVMFlutterVersion,
+			channel:     sdkVersions.FVMFlutterChannel,
+			installType: FVMName,
+		}))
+	}
+
+	if sdkVersions.ASDFFlutterVersion != nil || sdkVersions.ASDFFlutterChannel != "" {
+		sources = append(sources, newExactVersionSource(ASDFSourceName, flutterVersion{
+			version:     sdkVersions.ASDFFlutterVersion,
+			channel:     sdkVersions.ASDFFlutterChannel,
+			installType: ASDFName,
+		}))
+	}
+
+	if pubLock := sdkVersions.PubspecLockFlutterVersion; pubLock != nil {
+		if pubLock.Version != nil {
+			sources = append(sources, newExactVersionSource(PubspecLockSourceName, flutterVersion{version: pubLock.Version}))
+		} else if pubLock.Constraint != nil {
+			sources = append(sources, versionSource{
+				name:      PubspecLockSourceName,
+				specifier: versionSpecifier{raw: pubLock.String(), constraint: pubLock.Constraint},
+			})
+		}
+	}
+
+	if pubSpec := sdkVersions.PubspecFlutterVersion; pubSpec != nil {
+		if pubSpec.Version != nil {
+			sources = append(sources, newExactVersionSource(PubspecSourceName, flutterVersion{version: pubSpec.Version}))
+		} else if pubSpec.Constraint != nil {
+			sources = append(sources, versionSource{
+				name:      PubspecSourceName,
+				specifier: versionSpecifier{raw: pubSpec.String(), constraint: pubSpec.Constraint},
+			})
+		}
+	}
+
+	if melosVersion, ok, err := melosSDKPathVersion(projectDir); err != nil {
+		f.Debugf("read Flutter version from %s: %s", MelosSDKPathSourceName, err)
+	} else if ok {
+		sources = append(sources, newExactVersionSource(MelosSDKPathSourceName, melosVersion))
+	}
+
+	return sources, sdkVersions, nil
+}
+
+// trackSDKVersions reports the Flutter and Dart versions of the project, including the ones read by the Step itself.
+func trackSDKVersions(sdkVersions flutterproject.FlutterAndDartSDKVersions, sources []versionSource) {
+	var additionalSDKVersions tracker.AdditionalSDKVersions
+	for _, source := range sources {
+		switch source.name {
+		case MelosSDKPathSourceName:
+			additionalSDKVersions.MelosSDKPathFlutterVersion = source.specifier.version.version
+		case MetadataSourceName:
+			additionalSDKVersions.MetadataFlutterVersion = source.specifier.version.version
+		}
+	}
+
+	stepTracker := tracker.NewStepTracker(logv2.NewLogger(), env.NewRepository())
+	stepTracker.LogSDKVersions(sdkVersions, additionalSDKVersions)
+	stepTracker.Wait()
+}
+
+// versionSourceSelectable checks if the source of the priority key could be selected:
+// it is prioritized and no source with a higher priority declares a version.
+//
+// Used to skip resolving the sources which require the release catalogue, when their result is not used.
+func versionSourceSelectable(sources []versionSource, priority []string, key string) bool {
+	for _, k := range priority {
+		if k == key {
+			return true
+		}
+		for _, name := range versionSourcePriorityKeys[k] {
+			if slices.ContainsFunc(sources, func(s versionSource) bool { return s.name == name }) {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+func newExactVersionSource(name string, version flutterVersion) versionSource {
+	raw := version.versionString()
+	if raw == "" {
+		raw = version.channel
+	} else if version.channel != "" {
+		raw += "@" + version.channel
+	}
+	if version.fork != "" {
+		raw = version.fork + "/" + raw
+	}
+	return versionSource{
+		name:      name,
+		specifier: newVersionSpecifierFromVersion(version, raw),
+	}
+}
+
+// parseVersionSourcePriority parses the comma separated list of version source keys,
+// an empty input results in the default priority.
+func parseVersionSourcePriority(input string) ([]string, error) {
+	if strings.TrimSpace(input) == "" {
+		return DefaultVersionSourcePriority, nil
+	}
+
+	var priority []string
+	for _, key := range strings.Split(input, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		if _, ok := versionSourcePriorityKeys[key]; !ok {
+			return nil, fmt.Errorf("unknown version source: %s, available sources: %s", key, strings.Join(DefaultVersionSourcePriority, ", "))
+		}
+		if slices.Contains(priority, key) {
+			return nil, fmt.Errorf("version source %s is listed multiple times", key)
+		}
+		priority = append(priority, key)
+	}
+
+	return priority, nil
+}
+
+// prioritizeVersionSources orders the sources by the priority keys, sources not selected by any key are dropped.
+func prioritizeVersionSources(sources []versionSource, priority []string) []versionSource {
+	var prioritized []versionSource
+	for _, key := range priority {
+		for _, name := range versionSourcePriorityKeys[key] {
+			for _, source := range sources {
+				if source.name == name {
+					prioritized = append(prioritized, source)
+				}
+			}
+		}
+	}
+	return prioritized
+}
+
+// errFVMFlavorNotFound is returned if the selected flavor is not defined in the `.fvmrc` file.
+var errFVMFlavorNotFound = errors.New("FVM flavor not found")
+
+// readFVMRCVersion reads the Flutter version from the `.fvmrc` config file of FVM 3.
+//
+// If a flavor is given, the version of the flavor is read instead of the project version.
+// The `.fvm/fvm_config.json` file of earlier FVM versions is read by the flutterproject package.
+func readFVMRCVersion(projectDir, flavor string) (flutterVersion, error) {
+	content, err := os.ReadFile(filepath.Join(projectDir, fvmrcRelPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return flutterVersion{}, nil
+		}
+		return flutterVersion{}, err
+	}
+
+	var config struct {
+		Flutter string `json:"flutter"`
+		// FlutterSdkVersion is the key used by the legacy config format.
+		FlutterSdkVersion string            `json:"flutterSdkVersion"`
+		Flavors           map[string]string `json:"flavors"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return flutterVersion{}, err
+	}
+
+	if flavor != "" {
+		versionString, ok := config.Flavors[flavor]
+		if !ok {
+			var flavors []string
+			for name := range config.Flavors {
+				flavors = append(flavors, name)
+			}
+			slices.Sort(flavors)
+			return flutterVersion{}, fmt.Errorf("%w: %s, available flavors: %s", errFVMFlavorNotFound, flavor, strings.Join(flavors, ", "))
+		}
+		return parseFVMVersionString(versionString)
+	}
+
+	versionString := config.Flutter
+	if versionString == "" {
+		versionString = config.FlutterSdkVersion
+	}
+
+	return parseFVMVersionString(versionString)
+}
+
+// parseFVMVersionString parses FVM version notations: `3.22.0`, `3.22.0@beta`, `stable`, `mycompany/3.22.0`.
+func parseFVMVersionString(versionString string) (flutterVersion, error) {
+	versionString = strings.TrimSpace(versionString)
+	if versionString == "" {
+		return flutterVersion{}, nil
+	}
+
+	fork, forkVersion, isFork := strings.Cut(versionString, "/")
+	if isFork {
+		version, err := parseFVMVersionString(forkVersion)
+		if err != nil {
+			return flutterVersion{}, err
+		}
+		version.fork = fork
+		return version, nil
+	}
+
+	if slices.Contains(Channels, versionString) {
+		return flutterVersion{channel: versionString, installType: FVMName}, nil
+	}
+
+	version, channel, _ := strings.Cut(versionString, "@")
+	return newFlutterVersion(version, channel, FVMName)
+}
+
+// findVersionSourceConflicts returns the pairs of sources declaring incompatible Flutter versions.
+//
+// Exact versions conflict if they differ, an exact version conflicts with a constraint if it does not satisfy it.
+// Two constraints are not compared, as their compatibility depends on the available releases.
+// Inferred sources are not checked: for example the `.metadata` revision of a project created
+// with an older Flutter version is expected to differ from the declared version.
+func findVersionSourceConflicts(sources []versionSource) []versionSourceConflict {
+	var conflicts []versionSourceConflict
+	for i, a := range sources {
+		if a.inferred() {
+			continue
+		}
+		for _, b := range sources[i+1:] {
+			if !b.inferred() && !specifiersCompatible(a.specifier, b.specifier) {
+				conflicts = append(conflicts, versionSourceConflict{a: a, b: b})
+			}
+		}
+	}
+	return conflicts
+}
+
+func specifiersCompatible(a, b versionSpecifier) bool {
+	switch {
+	case a.isConstraint() && b.isConstraint():
+		return true
+	case a.isConstraint():
+		return b.version.version == nil || b.version.Satisfies(a.constraint)
+	case b.isConstraint():
+		return a.version.version == nil || a.version.Satisfies(b.constraint)
+	}
+
+	versionsMatch := a.version.version == nil || b.version.version == nil || a.version.Compare(b.version) == 0
+	channelsMatch := a.version.channel == "" || b.version.channel == "" || a.version.channel == b.version.channel
+	return versionsMatch && channelsMatch
+}
+
+// checkVersionSources prints the declared Flutter versions and reports the conflicts between them.
+//
+// If strict version consistency is required, conflicts fail the Step, otherwise only a warning is printed.
+func (f *FlutterInstaller) checkVersionSources(sources []versionSource, selected int) error {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	f.Println()
+	f.Infof("Flutter version candidates:")
+	f.Printf(versionSourceTableFormat, "Source", "Version", "")
+	for i, source := range sources {
+		mark := ""
+		if i == selected {
+			mark = "(selected)"
+		} else if source.inferred() {
+			mark = "(inferred, not checked for conflicts)"
+		}
+		f.Printf(versionSourceTableFormat, source.name, source.specifier, mark)
+	}
+	f.Println()
+
+	conflicts := findVersionSourceConflicts(sources)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	for _, conflict := range conflicts {
+		f.Warnf("Incompatible Flutter versions: %s", conflict)
+	}
+	if f.Input.StrictVersionConsistency {
+		return fmt.Errorf("%d incompatible Flutter version declarations found (strict_version_consistency is enabled), first: %s", len(conflicts), conflicts[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func testSource(t *testing.T, name, specifier string) versionSource {
+	s, err := newVersionSpecifier(specifier)
+	if err != nil {
+		t.Fatalf("newVersionSpecifier(%s) error = %v", specifier, err)
+	}
+	return versionSource{name: name, specifier: s}
+}
+
+func Test_findVersionSourceConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources [][2]string
+		want    int
+	}{
+		{
+			name:    "Same exact versions",
+			sources: [][2]string{{FVMRCSourceName, "3.22.0"}, {ASDFSourceName, "v3.22.0"}},
+		},
+		{
+			name:    "Different exact versions",
+			sources: [][2]string{{FVMRCSourceName, "3.22.0"}, {ASDFSourceName, "3.24.0"}},
+			want:    1,
+		},
+		{
+			name:    "Different channels",
+			sources: [][2]string{{FVMRCSourceName, "3.22.0 beta"}, {ASDFSourceName, "3.22.0 stable"}},
+			want:    1,
+		},
+		{
+			name:    "Channel only is compatible with a version",
+			sources: [][2]string{{InputSourceName, "stable"}, {FVMRCSourceName, "3.22.0"}},
+		},
+		{
+			name:    "Exact version outside of constraint",
+			sources: [][2]string{{FVMRCSourceName, "3.22.0"}, {PubspecSourceName, ">=3.24.0"}},
+			want:    1,
+		},
+		{
+			name:    "Exact version satisfying constraint",
+			sources: [][2]string{{FVMRCSourceName, "3.24.5"}, {PubspecSourceName, ">=3.24.0"}},
+		},
+		{
+			name:    "Constraints are not compared",
+			sources: [][2]string{{PubspecLockSourceName, ">=3.27.0"}, {PubspecSourceName, "<3.24.0"}},
+		},
+		{
+			name:    "Inferred sources are not checked",
+			sources: [][2]string{{FVMRCSourceName, "3.24.5"}, {MelosSDKPathSourceName, "3.22.0"}, {MetadataSourceName, "3.10.0"}},
+		},
+		{
+			name:    "Multiple conflicts",
+			sources: [][2]string{{FVMRCSourceName, "3.22.0"}, {ASDFSourceName, "3.24.0"}, {PubspecSourceName, ">=3.24.0"}},
+			want:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sources []versionSource
+			for _, s := range tt.sources {
+				sources = append(sources, testSource(t, s[0], s[1]))
+			}
+			if got := findVersionSourceConflicts(sources); len(got) != tt.want {
+				t.Errorf("findVersionSourceConflicts() = %v, want %d conflicts", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readFVMRCVersion(t *testing.T) {
+	const flavorsContent = `{"flutter": "3.24.5", "flavors": {"production": "3.22.0", "next": "beta", "custom": "mycompany/3.22.0"}}`
+
+	tests := []struct {
+		name    string
+		content string
+		flavor  string
+		want    flutterVersion
+		wantErr bool
+	}{
+		{
+			name:    "Version",
+			content: `{"flutter": "3.22.0"}`,
+			want:    testVersion("3.22.0", "", FVMName),
+		},
+		{
+			name:    "Version with channel",
+			content: `{"flutter": "3.22.0@beta", "flavors": {}}`,
+			want:    testVersion("3.22.0", "beta", FVMName),
+		},
+		{
+			name:    "Channel",
+			content: `{"flutter": "stable"}`,
+			want:    testVersion("", "stable", FVMName),
+		},
+		{
+			name:    "Legacy key",
+			content: `{"flutterSdkVersion": "3.10.6"}`,
+			want:    testVersion("3.10.6", "", FVMName),
+		},
+		{
+			name:    "Fork",
+			content: `{"flutter": "mycompany/3.22.0@beta"}`,
+			want:    testForkVersion("mycompany", "3.22.0", "beta"),
+		},
+		{
+			name:    "Flavor",
+			content: flavorsContent,
+			flavor:  "production",
+			want:    testVersion("3.22.0", "", FVMName),
+		},
+		{
+			name:    "Channel flavor",
+			content: flavorsContent,
+			flavor:  "next",
+			want:    testVersion("", "beta", FVMName),
+		},
+		{
+			name:    "Fork flavor",
+			content: flavorsContent,
+			flavor:  "custom",
+			want:    testForkVersion("mycompany", "3.22.0", ""),
+		},
+		{
+			name:    "Missing flavor",
+			content: flavorsContent,
+			flavor:  "staging",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid JSON",
+			content: `flutter: 3.22.0`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, fvmrcRelPath), []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readFVMRCVersion(dir, tt.flavor)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("readFVMRCVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !versionsEqual(got, tt.want) {
+				t.Errorf("readFVMRCVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		got, err := readFVMRCVersion(t.TempDir(), "")
+		if err != nil || got.version != nil || got.channel != "" {
+			t.Errorf("readFVMRCVersion() = %v, %v, want empty version", got, err)
+		}
+	})
+}
+
+func Test_parseVersionSourcePriority(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "Default",
+			input: "",
+			want:  DefaultVersionSourcePriority,
+		},
+		{
+			name:  "Custom order",
+			input: "fvm, Tool-Versions,pubspec,input",
+			want:  []string{"fvm", "tool-versions", "pubspec", "input"},
+		},
+		{
+			name:    "Unknown source",
+			input:   "fvm,flutter",
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate source",
+			input:   "fvm,input,fvm",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVersionSourcePriority(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVersionSourcePriority() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseVersionSourcePriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_prioritizeVersionSources(t *testing.T) {
+	sources := []versionSource{
+		testSource(t, InputSourceName, "stable"),
+		testSource(t, PubspecSourceName, ">=3.24.0"),
+		testSource(t, FVMConfigSourceName, "3.24.5"),
+		testSource(t, FVMRCSourceName, "3.24.5"),
+		testSource(t, MetadataSourceName, "3.22.3"),
+	}
+
+	tests := []struct {
+		name     string
+		priority []string
+		want     []string
+	}{
+		{
+			name:     "Default priority",
+			priority: DefaultVersionSourcePriority,
+			want:     []string{InputSourceName, FVMRCSourceName, FVMConfigSourceName, PubspecSourceName, MetadataSourceName},
+		},
+		{
+			name:     "Project files before input",
+			priority: []string{"fvm", "pubspec", "input"},
+			want:     []string{FVMRCSourceName, FVMConfigSourceName, PubspecSourceName, InputSourceName},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, source := range prioritizeVersionSources(sources, tt.priority) {
+				got = append(got, source.name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("prioritizeVersionSources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_versionSourceSelectable(t *testing.T) {
+	tests := []struct {
+		name     string
+		sources  [][2]string
+		priority []string
+		want     bool
+	}{
+		{
+			name:     "No other source",
+			priority: DefaultVersionSourcePriority,
+			want:     true,
+		},
+		{
+			name:     "Only lower priority sources",
+			sources:  [][2]string{{PubspecSourceName, ">=3.24.0"}},
+			priority: []string{"metadata", "pubspec"},
+			want:     true,
+		},
+		{
+			name:     "Higher priority source",
+			sources:  [][2]string{{PubspecSourceName, ">=3.24.0"}},
+			priority: DefaultVersionSourcePriority,
+		},
+		{
+			name:     "Not prioritized",
+			priority: []string{"input", "fvm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sources []versionSource
+			for _, s := range tt.sources {
+				sources = append(sources, testSource(t, s[0], s[1]))
+			}
+			if got := versionSourceSelectable(sources, tt.priority, "metadata"); got != tt.want {
+				t.Errorf("versionSourceSelectable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
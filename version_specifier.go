@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// versionSpecifier is a single entry of the ordered list of acceptable Flutter versions.
+//
+// It is either an exact version and/or channel (e.g. `3.24.5`, `stable`, `3.33.0-0.2.pre beta`)
+// or a version constraint (e.g. `3.24.x`, `>=3.22.0 <3.25.0`, `^3.24.0`).
+type versionSpecifier struct {
+	raw        string
+	version    flutterVersion
+	constraint *semver.Constraints
+}
+
+// constraintSpecifierRegexp matches wildcards (3.24.x, 3.*) and constraint operators (>=, <, ~, ^, !=).
+var constraintSpecifierRegexp = regexp.MustCompile(`(^|\.)[xX*](\.|$)|[<>=~^!]`)
+
+// fvmForkSpecifierRegexp matches FVM fork versions: `<fork>/<version>`, e.g. `mycompany/3.22.0`.
+var fvmForkSpecifierRegexp = regexp.MustCompile(`^([A-Za-z0-9_\-]+)/(\S+)$`)
+
+// NewVersionSpecifiers parses the ordered list of version specifiers, one entry per line.
+func NewVersionSpecifiers(input string) ([]versionSpecifier, error) {
+	var specifiers []versionSpecifier
+	for i, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		specifier, err := newVersionSpecifier(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d (%s): %w", i+1, line, err)
+		}
+		specifiers = append(specifiers, specifier)
+	}
+
+	return specifiers, nil
+}
+
+func newVersionSpecifier(input string) (versionSpecifier, error) {
+	if constraintSpecifierRegexp.MatchString(input) {
+		constraint, err := semver.NewConstraint(input)
+		if err != nil {
+			return versionSpecifier{}, fmt.Errorf("parse version constraint %s: %w", input, err)
+		}
+		return versionSpecifier{raw: input, constraint: constraint}, nil
+	}
+
+	if match := fvmForkSpecifierRegexp.FindStringSubmatch(input); match != nil {
+		version, err := parseFVMVersionString(match[2])
+		if err != nil {
+			return versionSpecifier{}, err
+		}
+		version.fork = match[1]
+		return versionSpecifier{raw: input, version: version}, nil
+	}
+
+	version, err := NewFlutterVersion(input)
+	if err != nil {
+		return versionSpecifier{}, err
+	}
+	return versionSpecifier{raw: input, version: version}, nil
+}
+
+// newVersionSpecifierFromVersion creates an exact specifier from an already parsed version.
+func newVersionSpecifierFromVersion(version flutterVersion, raw string) versionSpecifier {
+	return versionSpecifier{raw: raw, version: version}
+}
+
+func (s versionSpecifier) String() string {
+	return s.raw
+}
+
+func (s versionSpecifier) isConstraint() bool {
+	return s.constraint != nil
+}
+
+// isChannelOnly checks if the specifier only requires a channel (e.g. `stable`), without a version.
+func (s versionSpecifier) isChannelOnly() bool {
+	return !s.isConstraint() && s.version.version == nil && s.version.fork == "" && s.version.channel != ""
+}
+
+// matches checks if the given version satisfies the specifier.
+//
+// Exact specifiers require both version and channel to match (if not empty), and the same fork.
+// Constraints are only satisfied by versions of the official Flutter repository.
+func (s versionSpecifier) matches(v flutterVersion) bool {
+	if s.isConstraint() {
+		return v.fork == "" && v.Satisfies(s.constraint)
+	}
+
+	return (s.version.version == nil || s.version.Compare(v) == 0) &&
+		(s.version.channel == "" || s.version.channel == v.channel) &&
+		s.version.fork == v.fork
+}
+
+// bestMatch returns the version satisfying the specifier from the given versions.
+//
+// For exact specifiers, the required version is returned. For constraints, the highest matching version is returned.
+func (s versionSpecifier) bestMatch(versions []flutterVersion) (flutterVersion, bool) {
+	var best flutterVersion
+	found := false
+	for _, v := range versions {
+		if !s.matches(v) {
+			continue
+		}
+		if !s.isConstraint() {
+			return s.version, true
+		}
+
+		if !found || v.Compare(best) > 0 {
+			best, found = v, true
+		}
+	}
+
+	return best, found
+}
@@ -0,0 +1,156 @@
+package main
+
+import "testing"
+
+func Test_NewVersionSpecifiers(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		wantRaw        []string
+		wantConstraint []bool
+		wantErr        bool
+	}{
+		{
+			name:           "Single version",
+			input:          "3.24.5",
+			wantRaw:        []string{"3.24.5"},
+			wantConstraint: []bool{false},
+		},
+		{
+			name:           "Version, wildcard and channel",
+			input:          "3.24.5\n3.24.x\nstable",
+			wantRaw:        []string{"3.24.5", "3.24.x", "stable"},
+			wantConstraint: []bool{false, true, false},
+		},
+		{
+			name:           "Constraint and empty lines",
+			input:          "\n>=3.22.0 <3.25.0\n\n  beta  \n",
+			wantRaw:        []string{">=3.22.0 <3.25.0", "beta"},
+			wantConstraint: []bool{true, false},
+		},
+		{
+			name:           "Version with channel and hotfix",
+			input:          "3.33.0-0.2.pre beta\nv1.5.4-hotfix.2",
+			wantRaw:        []string{"3.33.0-0.2.pre beta", "v1.5.4-hotfix.2"},
+			wantConstraint: []bool{false, false},
+		},
+		{
+			name:    "Invalid entry",
+			input:   "3.24.5\nfoobar",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid constraint",
+			input:   ">=foo",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewVersionSpecifiers(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewVersionSpecifiers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.wantRaw) {
+				t.Errorf("NewVersionSpecifiers() = %v, want %v", got, tt.wantRaw)
+				return
+			}
+			for i, specifier := range got {
+				if specifier.String() != tt.wantRaw[i] {
+					t.Errorf("NewVersionSpecifiers()[%d] = %s, want %s", i, specifier, tt.wantRaw[i])
+				}
+				if specifier.isConstraint() != tt.wantConstraint[i] {
+					t.Errorf("NewVersionSpecifiers()[%d].isConstraint() = %v, want %v", i, specifier.isConstraint(), tt.wantConstraint[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_versionSpecifier_bestMatch(t *testing.T) {
+	versions := []flutterVersion{
+		testVersion("3.22.3", "stable", ""),
+		testVersion("3.24.3", "stable", ""),
+		testVersion("3.24.5", "stable", ""),
+		testVersion("3.27.0-0.1.pre", "beta", ""),
+		{channel: "beta"},
+		testForkVersion("mycompany", "3.29.0", "stable"),
+	}
+
+	tests := []struct {
+		name      string
+		specifier string
+		want      flutterVersion
+		wantFound bool
+	}{
+		{
+			name:      "Exact version",
+			specifier: "3.24.3",
+			want:      testVersion("3.24.3", "", ""),
+			wantFound: true,
+		},
+		{
+			name:      "Exact version with v prefix",
+			specifier: "v3.22.3",
+			want:      testVersion("v3.22.3", "", ""),
+			wantFound: true,
+		},
+		{
+			name:      "Missing exact version",
+			specifier: "3.24.4",
+		},
+		{
+			name:      "Wildcard picks highest",
+			specifier: "3.24.x",
+			want:      testVersion("3.24.5", "stable", ""),
+			wantFound: true,
+		},
+		{
+			name:      "Constraint",
+			specifier: ">=3.22.0 <3.24.0",
+			want:      testVersion("3.22.3", "stable", ""),
+			wantFound: true,
+		},
+		{
+			name:      "Channel",
+			specifier: "beta",
+			want:      flutterVersion{channel: "beta"},
+			wantFound: true,
+		},
+		{
+			name:      "Unsatisfiable constraint",
+			specifier: ">=4.0.0",
+		},
+		{
+			name:      "Constraint excludes forks",
+			specifier: ">=3.28.0",
+		},
+		{
+			name:      "Fork version",
+			specifier: "mycompany/3.29.0",
+			want:      testForkVersion("mycompany", "3.29.0", ""),
+			wantFound: true,
+		},
+		{
+			name:      "Fork version of other fork",
+			specifier: "othercompany/3.29.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specifier, err := newVersionSpecifier(tt.specifier)
+			if err != nil {
+				t.Fatalf("newVersionSpecifier() error = %v", err)
+			}
+			got, found := specifier.bestMatch(versions)
+			if found != tt.wantFound {
+				t.Errorf("bestMatch() found = %v, want %v", found, tt.wantFound)
+				return
+			}
+			if !versionsEqual(got, tt.want) {
+				t.Errorf("bestMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
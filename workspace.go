@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bitrise-io/go-flutter/flutterproject"
+	"github.com/bitrise-io/go-flutter/fluttersdk"
+	"github.com/bitrise-io/go-utils/v2/fileutil"
+	"github.com/bitrise-io/go-utils/v2/pathutil"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	WorkspaceSourceName = "workspace"
+	melosConfigRelPath  = "melos.yaml"
+	pubspecRelPath      = "pubspec.yaml"
+)
+
+// workspacePackage is a package of a melos or pub workspace with its SDK constraints.
+type workspacePackage struct {
+	name string
+	dir  string
+	// flutter and dart are the SDK constraints from the pubspec.yaml environment section, nil if not specified.
+	flutter *semver.Constraints
+	dart    *semver.Constraints
+}
+
+func (p workspacePackage) String() string {
+	var constraints []string
+	if p.flutter != nil {
+		constraints = append(constraints, "flutter "+p.flutter.String())
+	}
+	if p.dart != nil {
+		constraints = append(constraints, "sdk "+p.dart.String())
+	}
+	return fmt.Sprintf("%s (%s): %s", p.name, p.dir, strings.Join(constraints, ", "))
+}
+
+// satisfiedBy checks if the release satisfies the Flutter and Dart SDK constraints of the package.
+func (p workspacePackage) satisfiedBy(release flutterRelease) bool {
+	return (p.flutter == nil || release.version.Satisfies(p.flutter)) && p.dartSatisfiedBy(release)
+}
+
+// dartSatisfiedBy checks if the Dart SDK bundled with the release satisfies the Dart SDK constraint of the package.
+func (p workspacePackage) dartSatisfiedBy(release flutterRelease) bool {
+	return p.dart == nil || (release.dartVersion != nil && satisfiesConstraint(release.dartVersion, p.dart))
+}
+
+// findWorkspacePackageDirs returns the package directories of a melos (melos.yaml `packages`)
+// or pub workspace (pubspec.yaml `workspace`) rooted at the project directory, including the root package.
+//
+// Returns an empty list if the project is not a workspace.
+func findWorkspacePackageDirs(projectDir string) ([]string, error) {
+	var melosConfig struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := readYAMLIfExists(filepath.Join(projectDir, melosConfigRelPath), &melosConfig); err != nil {
+		return nil, fmt.Errorf("read %s: %w", melosConfigRelPath, err)
+	}
+
+	var pubspec struct {
+		Workspace []string `yaml:"workspace"`
+	}
+	if err := readYAMLIfExists(filepath.Join(projectDir, pubspecRelPath), &pubspec); err != nil {
+		return nil, fmt.Errorf("read %s: %w", pubspecRelPath, err)
+	}
+
+	patterns := append(melosConfig.Packages, pubspec.Workspace...)
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	dirs := []string{projectDir}
+	for _, pattern := range patterns {
+		matches, err := expandPackagePattern(projectDir, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("expand package pattern %s: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if !slices.Contains(dirs, match) {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+
+	return dirs, nil
+}
+
+// expandPackagePattern returns the directories with a pubspec.yaml matching the glob pattern.
+//
+// Besides the standard glob syntax, the `**` (any number of directories) wildcard of melos is supported.
+func expandPackagePattern(projectDir, pattern string) ([]string, error) {
+	pattern = filepath.Clean(filepath.Join(projectDir, pattern))
+
+	prefix, _, recursive := strings.Cut(pattern, "**")
+	if !recursive {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return slices.DeleteFunc(matches, func(dir string) bool { return !hasPubspec(dir) }), nil
+	}
+
+	prefix = filepath.Clean(prefix)
+	var dirs []string
+	err := filepath.WalkDir(prefix, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if name := d.Name(); path != prefix && (strings.HasPrefix(name, ".") || name == "build") {
+			return filepath.SkipDir
+		}
+		if hasPubspec(path) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	return dirs, err
+}
+
+func hasPubspec(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, pubspecRelPath))
+	return err == nil && !info.IsDir()
+}
+
+func readYAMLIfExists(pth string, v any) error {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return yaml.Unmarshal(content, v)
+}
+
+// readWorkspacePackages reads the name and SDK constraints of the packages.
+func readWorkspacePackages(dirs []string) ([]workspacePackage, error) {
+	var packages []workspacePackage
+	for _, dir := range dirs {
+		proj, err := flutterproject.New(dir, fileutil.NewFileManager(), pathutil.NewPathChecker(), fluttersdk.NewSDKVersionFinder())
+		if err != nil {
+			return nil, fmt.Errorf("open package %s: %s", dir, err)
+		}
+		sdkVersions, err := proj.FlutterAndDartSDKVersions()
+		if err != nil {
+			return nil, fmt.Errorf("get Flutter and Dart SDK versions of package %s: %s", dir, err)
+		}
+
+		pkg := workspacePackage{
+			name: proj.Pubspec().Name,
+			dir:  dir,
+		}
+		if v := sdkVersions.PubspecFlutterVersion; v != nil {
+			pkg.flutter = newConstraints(v.Version, v.Constraint)
+		}
+		if v := sdkVersions.PubspecDartVersion; v != nil {
+			pkg.dart = newConstraints(v.Version, v.Constraint)
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// newConstraints converts the exact version or constraint of a pubspec environment entry to a constraint.
+func newConstraints(version *semver.Version, constraint *semver.Constraints) *semver.Constraints {
+	if constraint != nil {
+		return constraint
+	}
+	if version == nil {
+		return nil
+	}
+
+	exact, err := semver.NewConstraint("=" + version.String())
+	if err != nil {
+		return nil
+	}
+	return exact
+}
+
+// dartSDKConstraint maps the Dart SDK constraints of the packages to the Flutter releases bundling a Dart SDK
+// which satisfies all of them, as ranges of consecutive releases.
+//
+// Each release is checked against every package, so the releases falling in a gap of the constraints
+// (like `^2.19.0 || ^3.1.0`) split the ranges.
+// Stable releases are preferred, beta releases are only considered if no stable release satisfies all packages.
+func dartSDKConstraint(packages []workspacePackage, catalogue releaseCatalogue) ([]string, error) {
+	var candidates []flutterRelease
+	for _, pkg := range packages {
+		if pkg.dart != nil {
+			candidates = catalogue.byDartVersion(pkg.dart)
+			break
+		}
+	}
+	candidates = slices.DeleteFunc(candidates, func(release flutterRelease) bool {
+		return slices.ContainsFunc(packages, func(p workspacePackage) bool { return !p.dartSatisfiedBy(release) })
+	})
+
+	for _, channel := range []string{"stable", "beta"} {
+		channelReleases := slices.DeleteFunc(slices.Clone(catalogue.releases), func(release flutterRelease) bool {
+			return release.version.channel != channel
+		})
+		slices.SortFunc(channelReleases, func(a, b flutterRelease) int {
+			return b.version.Compare(a.version)
+		})
+
+		var ranges []string
+		var lowest, highest flutterVersion
+		inRange := false
+		for _, release := range channelReleases {
+			matching := slices.ContainsFunc(candidates, func(c flutterRelease) bool {
+				return c.version.channel == channel && c.version.Compare(release.version) == 0
+			})
+			if matching {
+				if !inRange {
+					highest, inRange = release.version, true
+				}
+				lowest = release.version
+				continue
+			}
+			if inRange {
+				ranges = append(ranges, fmt.Sprintf(">=%s, <=%s", lowest.versionString(), highest.versionString()))
+				inRange = false
+			}
+		}
+		if inRange {
+			ranges = append(ranges, fmt.Sprintf(">=%s, <=%s", lowest.versionString(), highest.versionString()))
+		}
+		if len(ranges) > 0 {
+			return ranges, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no Flutter release bundles a Dart SDK satisfying the constraints of all %d packages", len(packages))
+}
+
+// workspaceConstraint combines the SDK constraints of the packages into a single Flutter version constraint.
+//
+// The Dart SDK constraints are mapped to Flutter versions using the releases, which are only loaded if a package has one.
+// Returns nil if no package constrains the SDK version.
+func workspaceConstraint(packages []workspacePackage, loadReleases func() (releaseCatalogue, error)) (*semver.Constraints, error) {
+	var constraints []string
+	for _, pkg := range packages {
+		if pkg.flutter != nil {
+			constraints = append(constraints, pkg.flutter.String())
+		}
+	}
+	if slices.ContainsFunc(packages, func(p workspacePackage) bool { return p.dart != nil }) {
+		catalogue, err := loadReleases()
+		if err != nil {
+			return nil, err
+		}
+		dartRanges, err := dartSDKConstraint(packages, catalogue)
+		if err != nil {
+			return nil, err
+		}
+		// Every range is combined with the Flutter constraints, as `||` binds looser than `,`.
+		for i, dartRange := range dartRanges {
+			dartRanges[i] = strings.Join(append(slices.Clone(constraints), dartRange), ", ")
+		}
+		return semver.NewConstraint(strings.Join(dartRanges, " || "))
+	}
+	if len(constraints) == 0 {
+		return nil, nil
+	}
+
+	return semver.NewConstraint(strings.Join(constraints, ", "))
+}
+
+// boundingPackages returns the latest release satisfying the workspace constraint, which is installed if no installed SDK
+// satisfies it, and the packages setting this bound: the ones excluding the next release of the channel.
+//
+// Returns no packages if the latest release of the channel satisfies the constraint, false if no release does.
+func boundingPackages(packages []workspacePackage, constraint *semver.Constraints, catalogue releaseCatalogue) (flutterRelease, []workspacePackage, bool) {
+	var selected flutterRelease
+	found := false
+	for _, release := range catalogue.releases {
+		if release.version.Satisfies(constraint) && (!found || release.version.Compare(selected.version) > 0) {
+			selected, found = release, true
+		}
+	}
+	if !found {
+		return flutterRelease{}, nil, false
+	}
+
+	var next flutterRelease
+	hasNext := false
+	for _, release := range catalogue.releases {
+		if release.version.channel != selected.version.channel || release.version.Compare(selected.version) <= 0 {
+			continue
+		}
+		if !hasNext || release.version.Compare(next.version) < 0 {
+			next, hasNext = release, true
+		}
+	}
+	if !hasNext {
+		return selected, nil, true
+	}
+
+	var bounding []workspacePackage
+	for _, pkg := range packages {
+		if !pkg.satisfiedBy(next) {
+			bounding = append(bounding, pkg)
+		}
+	}
+	return selected, bounding, true
+}
+
+// workspaceVersionSource returns the Flutter version constraint satisfying every package of a melos or pub workspace.
+//
+// The constraint is resolved to a release at install time, so an already installed SDK satisfying it can be used.
+// Returns false if the project is not a workspace or its packages do not constrain the SDK version.
+func (f *FlutterInstaller) workspaceVersionSource(projectDir string) (versionSource, bool, error) {
+	dirs, err := findWorkspacePackageDirs(projectDir)
+	if err != nil {
+		return versionSource{}, false, err
+	}
+	if len(dirs) < 2 {
+		return versionSource{}, false, nil
+	}
+
+	packages, err := readWorkspacePackages(dirs)
+	if err != nil {
+		return versionSource{}, false, err
+	}
+
+	constraint, err := workspaceConstraint(packages, f.releaseCatalogue)
+	if err != nil {
+		for _, pkg := range packages {
+			f.Printf("- %s", pkg)
+		}
+		return versionSource{}, false, err
+	}
+	if constraint == nil {
+		return versionSource{}, false, nil
+	}
+
+	f.Infof("Workspace with %d packages, Flutter version satisfying all of them: %s", len(packages), constraint)
+	if catalogue, err := f.releaseCatalogue(); err != nil {
+		f.Debugf("Failed to list Flutter releases, not reporting the constraining packages: %s", err)
+	} else if selected, bounding, ok := boundingPackages(packages, constraint, catalogue); ok {
+		if len(bounding) == 0 {
+			f.Printf("The latest %s release (%s) satisfies all packages", selected.version.channel, selected.version.versionString())
+		} else {
+			f.Printf("Latest satisfying release: %s, limited by:", selected.version.versionString())
+			for _, pkg := range bounding {
+				f.Printf("- %s", pkg)
+			}
+		}
+	}
+
+	return versionSource{
+		name:      WorkspaceSourceName,
+		specifier: versionSpecifier{raw: constraint.String(), constraint: constraint},
+	}, true, nil
+}
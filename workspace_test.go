@@ -0,0 +1,264 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func writeTestFile(t *testing.T, pth, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(pth), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pth, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_findWorkspacePackageDirs(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "Not a workspace",
+			files: map[string]string{
+				"pubspec.yaml": "name: app\n",
+			},
+		},
+		{
+			name: "Melos workspace",
+			files: map[string]string{
+				"pubspec.yaml":                    "name: root\n",
+				"melos.yaml":                      "name: root\npackages:\n  - apps/*\n  - packages/**\n",
+				"apps/app/pubspec.yaml":           "name: app\n",
+				"apps/README.md":                  "",
+				"packages/a/pubspec.yaml":         "name: a\n",
+				"packages/group/b/pubspec.yaml":   "name: b\n",
+				"packages/.hidden/c/pubspec.yaml": "name: c\n",
+				"packages/a/build/d/pubspec.yaml": "name: d\n",
+			},
+			want: []string{".", "apps/app", "packages/a", "packages/group/b"},
+		},
+		{
+			name: "Pub workspace",
+			files: map[string]string{
+				"pubspec.yaml":            "name: root\nenvironment:\n  sdk: ^3.6.0\nworkspace:\n  - packages/a\n  - packages/b\n",
+				"packages/a/pubspec.yaml": "name: a\nresolution: workspace\n",
+				"packages/b/pubspec.yaml": "name: b\nresolution: workspace\n",
+			},
+			want: []string{".", "packages/a", "packages/b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for pth, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, pth), content)
+			}
+
+			got, err := findWorkspacePackageDirs(dir)
+			if err != nil {
+				t.Fatalf("findWorkspacePackageDirs() error = %v", err)
+			}
+			var gotRel []string
+			for _, pth := range got {
+				rel, err := filepath.Rel(dir, pth)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotRel = append(gotRel, rel)
+			}
+			slices.Sort(gotRel)
+			if !slices.Equal(gotRel, tt.want) {
+				t.Errorf("findWorkspacePackageDirs() = %v, want %v", gotRel, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workspaceConstraint(t *testing.T) {
+	release := func(version, channel, dart string) flutterRelease {
+		return flutterRelease{version: testVersion(version, channel, ""), dartVersion: semver.MustParse(dart)}
+	}
+	releases := []flutterRelease{
+		release("3.22.3", "stable", "3.4.4"),
+		release("3.27.4", "stable", "3.6.2"),
+		release("3.24.5", "stable", "3.5.4"),
+		release("3.22.0", "stable", "3.4.0"),
+		release("3.29.0-0.1.pre", "beta", "3.7.0"),
+		release("3.7.12", "stable", "2.19.6"),
+		release("3.10.6", "stable", "3.0.6"),
+		release("3.13.9", "stable", "3.1.5"),
+	}
+
+	tests := []struct {
+		name         string
+		packages     []workspacePackage
+		want         string
+		wantReleases bool
+		wantErr      bool
+	}{
+		{
+			name: "Unconstrained",
+			packages: []workspacePackage{
+				{name: "a", dir: "a"},
+			},
+		},
+		{
+			name: "Flutter constraints",
+			packages: []workspacePackage{
+				{name: "a", dir: "a", flutter: mustConstraint(t, ">=3.22.0")},
+				{name: "b", dir: "b", flutter: mustConstraint(t, "<3.25.0")},
+			},
+			want: "3.24.5",
+		},
+		{
+			name: "Dart constraint",
+			packages: []workspacePackage{
+				{name: "a", dir: "a", flutter: mustConstraint(t, ">=3.22.0")},
+				{name: "b", dir: "b", dart: mustConstraint(t, ">=3.4.0 <3.5.0")},
+			},
+			want:         "3.22.3",
+			wantReleases: true,
+		},
+		{
+			name: "Gap between the Dart constraints",
+			packages: []workspacePackage{
+				{name: "a", dir: "a", dart: mustConstraint(t, "^2.19.0 || ^3.1.0")},
+				{name: "b", dir: "b", flutter: mustConstraint(t, "<3.13.0")},
+			},
+			want:         "3.7.12",
+			wantReleases: true,
+		},
+		{
+			name: "Dart constraint only satisfied by beta",
+			packages: []workspacePackage{
+				{name: "a", dir: "a", dart: mustConstraint(t, ">=3.7.0-0 <4.0.0")},
+			},
+			want:         "3.29.0-0.1.pre",
+			wantReleases: true,
+		},
+		{
+			name: "Unsatisfiable Dart constraint",
+			packages: []workspacePackage{
+				{name: "a", dir: "a", dart: mustConstraint(t, ">=4.0.0")},
+			},
+			wantReleases: true,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded := false
+			got, err := workspaceConstraint(tt.packages, func() (releaseCatalogue, error) {
+				loaded = true
+				return releaseCatalogue{releases: releases}, nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("workspaceConstraint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if loaded != tt.wantReleases {
+				t.Errorf("workspaceConstraint() loaded releases = %v, want %v", loaded, tt.wantReleases)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("workspaceConstraint() = %s, want no constraint", got)
+				}
+				return
+			}
+
+			specifier := versionSpecifier{raw: got.String(), constraint: got}
+			var versions []flutterVersion
+			for _, release := range releases {
+				versions = append(versions, release.version)
+			}
+			if best, found := specifier.bestMatch(versions); !found || best.versionString() != tt.want {
+				t.Errorf("workspaceConstraint() = %s, best match %s, want %s", got, best.versionString(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_boundingPackages(t *testing.T) {
+	release := func(version, channel, dart string) flutterRelease {
+		return flutterRelease{version: testVersion(version, channel, ""), dartVersion: semver.MustParse(dart)}
+	}
+	catalogue := releaseCatalogue{releases: []flutterRelease{
+		release("3.22.3", "stable", "3.4.4"),
+		release("3.24.5", "stable", "3.5.4"),
+		release("3.27.4", "stable", "3.6.2"),
+		release("3.29.0-0.1.pre", "beta", "3.7.0"),
+	}}
+	a := workspacePackage{name: "a", dir: "a", flutter: mustConstraint(t, ">=3.22.0")}
+	b := workspacePackage{name: "b", dir: "b", dart: mustConstraint(t, "<3.6.0")}
+	c := workspacePackage{name: "c", dir: "c", flutter: mustConstraint(t, "<3.25.0")}
+
+	tests := []struct {
+		name         string
+		packages     []workspacePackage
+		constraint   string
+		wantSelected string
+		wantBounding []string
+		wantFound    bool
+	}{
+		{
+			name:         "Bound set by a Flutter and a Dart constraint",
+			packages:     []workspacePackage{a, b, c},
+			constraint:   ">=3.22.0, <3.25.0",
+			wantSelected: "3.24.5",
+			wantBounding: []string{"b", "c"},
+			wantFound:    true,
+		},
+		{
+			name:         "Latest release satisfies all packages",
+			packages:     []workspacePackage{a},
+			constraint:   ">=3.22.0",
+			wantSelected: "3.27.4",
+			wantFound:    true,
+		},
+		{
+			name:       "No release satisfies the constraint",
+			packages:   []workspacePackage{a},
+			constraint: ">=4.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, bounding, found := boundingPackages(tt.packages, mustConstraint(t, tt.constraint), catalogue)
+			if found != tt.wantFound {
+				t.Fatalf("boundingPackages() found = %v, want %v", found, tt.wantFound)
+			}
+			if !found {
+				return
+			}
+			if got := selected.version.versionString(); got != tt.wantSelected {
+				t.Errorf("boundingPackages() selected = %s, want %s", got, tt.wantSelected)
+			}
+			var names []string
+			for _, pkg := range bounding {
+				names = append(names, pkg.name)
+			}
+			if !slices.Equal(names, tt.wantBounding) {
+				t.Errorf("boundingPackages() bounding = %v, want %v", names, tt.wantBounding)
+			}
+		})
+	}
+}
+
+func mustConstraint(t *testing.T, c string) *semver.Constraints {
+	t.Helper()
+	constraint, err := semver.NewConstraint(c)
+	if err != nil {
+		t.Fatalf("semver.NewConstraint(%s) error = %v", c, err)
+	}
+	return constraint
+}